@@ -2,11 +2,13 @@ module github.com/Manu343726/cucaracha
 
 go 1.22.0
 
-require golang.org/x/exp v0.0.0-20240222234643-814bf88cf225
+require (
+	github.com/stretchr/testify v1.9.0
+	golang.org/x/exp v0.0.0-20240222234643-814bf88cf225
+)
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
-	github.com/stretchr/testify v1.9.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
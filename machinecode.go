@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/Manu343726/cucaracha/pkg/hw/cpu/mc"
+	"github.com/Manu343726/cucaracha/pkg/utils"
+)
+
+// Prints the encoding and operands of an instruction. If expected operand values are given,
+// operands whose value does not match are flagged as not round-tripping.
+func printInstruction(instr *mc.Instruction, encoding uint32, expectedValues []uint64) bool {
+	roundTrips := true
+
+	fmt.Printf("instruction: %v\n", instr)
+	fmt.Printf("encoding: %v (%v)\n\n", utils.FormatUintHex(uint64(encoding), 8), utils.FormatUintBinary(uint64(encoding), 32))
+	fmt.Println(instr.PrettyPrint(2))
+	fmt.Println("operands:")
+
+	if len(instr.Descriptor.Operands) <= 0 {
+		fmt.Println("  (none)")
+	}
+
+	for i, operand := range instr.Descriptor.Operands {
+		value := instr.OperandValues[i]
+		fmt.Printf("  [%v] %v: %v (%v)", i, operand, utils.FormatUintHex(value, operand.EncodingBits/4), value)
+
+		if expectedValues != nil && expectedValues[i] != value {
+			fmt.Printf(" does not round-trip, %v was truncated to %v bits", expectedValues[i], operand.EncodingBits)
+			roundTrips = false
+		}
+
+		fmt.Println()
+	}
+
+	return roundTrips
+}
+
+// Assembles an instruction and checks it decodes back into the same instruction, returns the process exit code
+func encodeCommand(args []string) int {
+	if len(args) <= 0 {
+		fmt.Println("expected an instruction argument (e.g. ADD 0x01, 0x02, 0x03)")
+		return 2
+	}
+
+	instr, err := mc.ParseInstruction(strings.Join(args, " "))
+
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	encoding := instr.Encode()
+	decoded, err := mc.DecodeInstruction(encoding)
+
+	if err != nil {
+		fmt.Printf("instruction '%v' encoded as %v cannot be decoded: %v\n", instr, utils.FormatUintHex(uint64(encoding), 8), err)
+		return 1
+	}
+
+	if !printInstruction(decoded, encoding, instr.OperandValues) {
+		return 1
+	}
+
+	return 0
+}
+
+// Decodes an instruction and checks it encodes back into the same binary representation, returns the process exit code
+func decodeCommand(args []string) int {
+	if len(args) != 1 {
+		fmt.Println("expected one 32 bit instruction encoding argument (e.g. 0x00302015)")
+		return 2
+	}
+
+	encoding, err := strconv.ParseUint(args[0], 0, 32)
+
+	if err != nil {
+		fmt.Printf("could not parse instruction encoding '%v': %v\n", args[0], err)
+		return 1
+	}
+
+	instr, err := mc.DecodeInstruction(uint32(encoding))
+
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+
+	printInstruction(instr, uint32(encoding), nil)
+
+	if reencoded := instr.Encode(); reencoded != uint32(encoding) {
+		fmt.Printf("\nencoding does not round-trip, re-encoded as %v (unused bits are not preserved)\n", utils.FormatUintHex(uint64(reencoded), 8))
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeCommand(t *testing.T) {
+	code, output := captureStdout(t, func() int { return decodeCommand([]string{"0x00302015"}) })
+
+	assert.Equal(t, 0, code)
+	assert.Contains(t, output, "encoding: 0x00302015")
+}
+
+func TestDecodeCommand_InvalidOpCode(t *testing.T) {
+	code, output := captureStdout(t, func() int { return decodeCommand([]string{"0xffffffff"}) })
+
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "invalid instruction opcode: 15 (hex: 0xf, bin: 1111)\n", output)
+}
+
+func TestMachineCodeCommands_Usage(t *testing.T) {
+	code, _ := captureStdout(t, func() int { return encodeCommand(nil) })
+	assert.Equal(t, 2, code)
+
+	code, _ = captureStdout(t, func() int { return decodeCommand(nil) })
+	assert.Equal(t, 2, code)
+
+	code, _ = captureStdout(t, func() int { return decodeCommand([]string{"zzz"}) })
+	assert.Equal(t, 1, code)
+}
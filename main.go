@@ -1,7 +1,15 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
 	"strings"
 
 	"github.com/Manu343726/cucaracha/pkg/hw/cpu"
@@ -13,85 +21,284 @@ type Register = string
 type MicroCpu = cpu.MicroCpu[Register, Word, Float]
 type MicroCpuFactories = cpu.MicroCpuFactories[Register, Word, Float]
 
-type StdoutTracer struct{}
+// Discards all traces
+type NullTracer struct{}
 
-func (t *StdoutTracer) SaveTrace(trace *Trace) {
-	buffer := strings.Builder{}
+func (t *NullTracer) SaveTrace(trace *Trace) {}
 
-	for i := 0; i < trace.Depth(); i++ {
-		buffer.WriteByte(' ')
+func makeTracer(format string, output io.Writer) (Tracer, error) {
+	switch format {
+	case "text":
+		return MakeTextTracer(output), nil
+	case "json":
+		return MakeJSONTracer(output), nil
+	case "none":
+		return &NullTracer{}, nil
 	}
 
-	buffer.WriteString(trace.String())
-	fmt.Println(buffer.String())
+	return nil, fmt.Errorf("unknown tracer '%v', expected one of text, json, none", format)
 }
 
-func makeCpu() MicroCpu {
-	tracer := MakeTracerWithContextStack(&StdoutTracer{})
-
-	settings := cpu.MicroCpuSettings{
-		TotalInternalWordRegisters: 4,
-		TotalPublicWordRegisters:   8,
-		TotalFloatRegisters:        8,
-		TotalMemory:                1024,
-	}
-
-	cpuFactories := MicroCpuFactories{
-		StateRegisterNames: cpu.StateRegisters[Register]{
-			ProgramCounter: "pc",
-			StackPointer:   "sp",
-			FramePointer:   "fp",
-			LinkRegister:   "lr",
-		},
-		InternalWordRegisterName: func(index int) Register {
-			return fmt.Sprintf("_w%v", index)
-		},
-		PublicWordRegisterName: func(index int) Register {
-			return fmt.Sprintf("w%v", index)
-		},
-		FloatRegisterName: func(index int) Register {
-			return fmt.Sprintf("f%v", index)
-		},
-		StateRegisters:        TracedRegisterBankFactory[Register, Word](cpu.MakeRegisters[Register, Word], "state registers", tracer),
-		InternalWordRegisters: TracedRegisterBankFactory[Register, Word](cpu.MakeRegisters[Register, Word], "internal word registers", tracer),
-		PublicWordRegisters:   TracedRegisterBankFactory[Register, Word](cpu.MakeRegisters[Register, Word], "public word registers", tracer),
-		FloatRegisters:        TracedRegisterBankFactory[Register, Float](cpu.MakeRegisters[Register, Float], "float registers", tracer),
-		WordMov:               TracedRegisterInterchangeFactory[Register, Word](cpu.MakeRegisterInterchange[Register, Word], "word mov", tracer),
-		FloatMov:              TracedRegisterInterchangeFactory[Register, Float](cpu.MakeRegisterInterchange[Register, Float], "float mov", tracer),
-		WordToFloat:           TracedRegisterConversionFactory[Register, Word, Float](cpu.MakeRegisterConversion[Register, Word, Float], "word to float conversion", tracer),
-		FloatToWord:           TracedRegisterConversionFactory[Register, Float, Word](cpu.MakeRegisterConversion[Register, Float, Word], "float to word conversion", tracer),
-		WordAlu:               cpu.MakeIntegerAlu[Register, Word],
-		FloatAlu:              cpu.MakeFloatAlu[Register, Float],
-		MemoryBus: TracedMemoryBusFactory[Word](func() cpu.MemoryBus[Word] {
-			return cpu.MakeMemory[Word](settings.TotalMemory)
-		}, "main memory bus", tracer),
-		MemoryAccess: TracedMemoryAccessFactory[Register, Word](cpu.MakeMemoryAccess[Register, Word], "main memory access", tracer),
+var settings = cpu.MicroCpuSettings{
+	TotalInternalWordRegisters: 4,
+	TotalPublicWordRegisters:   8,
+	TotalFloatRegisters:        8,
+	TotalMemory:                1024,
+}
+
+// Replaces the default MicroCpu factories with traced ones
+func traceFactories(factories *MicroCpuFactories, tracer TracerWithContextStack) {
+	factories.StateRegisters = TracedRegisterBankFactory[Register, Word](factories.StateRegisters, "state registers", tracer)
+	factories.InternalWordRegisters = TracedRegisterBankFactory[Register, Word](factories.InternalWordRegisters, "internal word registers", tracer)
+	factories.PublicWordRegisters = TracedRegisterBankFactory[Register, Word](factories.PublicWordRegisters, "public word registers", tracer)
+	factories.FloatRegisters = TracedRegisterBankFactory[Register, Float](factories.FloatRegisters, "float registers", tracer)
+	factories.WordMov = TracedRegisterInterchangeFactory[Register, Word](factories.WordMov, "word mov", tracer)
+	factories.FloatMov = TracedRegisterInterchangeFactory[Register, Float](factories.FloatMov, "float mov", tracer)
+	factories.WordToFloat = TracedRegisterConversionFactory[Register, Word, Float](factories.WordToFloat, "word to float conversion", tracer)
+	factories.FloatToWord = TracedRegisterConversionFactory[Register, Float, Word](factories.FloatToWord, "float to word conversion", tracer)
+	factories.MemoryBus = TracedMemoryBusFactory[Word](factories.MemoryBus, "main memory bus", tracer)
+	factories.MemoryAccess = TracedMemoryAccessFactory[Register, Word](factories.MemoryAccess, "main memory access", tracer)
+}
+
+// Builds the REPL CPU. If memoryLogSize is greater than zero, the last memoryLogSize memory accesses are
+// recorded by a [cpu.MemoryAccessLog] wrapping the CPU memory bus
+func makeCpu(tracer TracerWithContextStack, memoryLogSize int) MicroCpu {
+	cpuFactories := cpu.MakeDefaultMicroCpuFactories[Word, Float](settings)
+	traceFactories(&cpuFactories, tracer)
+
+	if memoryLogSize > 0 {
+		cpuFactories.MemoryBus = cpu.MemoryAccessLogFactory[Word](cpuFactories.MemoryBus, memoryLogSize)
 	}
 
 	return MakeTracedMicroCpu[Register, Word, Float](cpu.MakeMicroCpu[Register, Word, Float](settings, cpuFactories), "main cpu", tracer)
 }
 
-func main() {
-	myCpu := makeCpu()
+const replPrompt = "> "
+
+// Splits a comma separated list of names, ignoring empty entries
+func splitList(list string) []string {
+	names := make([]string, 0)
+
+	for _, name := range strings.Split(list, ",") {
+		if name = strings.TrimSpace(name); len(name) > 0 {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+func printHelp() {
+	fmt.Println("MicroCpu instructions:")
+
+	for _, instruction := range cpu.MicroCpuInstructions {
+		fmt.Printf("  %v %v: %v\n", instruction.Mnemonic, instruction.Arguments, instruction.Description)
+	}
+
+	fmt.Println()
+	fmt.Println("Program instructions (program files only):")
+
+	for _, instruction := range cpu.ProgramInstructions {
+		fmt.Printf("  %v %v: %v\n", instruction.Mnemonic, instruction.Arguments, instruction.Description)
+	}
+
+	fmt.Println("  <name>: define a label at the current line")
+	fmt.Println()
+	fmt.Println("REPL commands:")
+	fmt.Println("  .help: show this help")
+	fmt.Println("  .meminfo <address>: show the last accesses to a memory address and the commands that did them (requires -memory-log)")
+	fmt.Println("  .quit: exit the REPL")
+}
+
+// Default limit of lines a program can run (See [cpu.MakeProgramInterpreterWithStepLimit])
+const defaultMaxSteps = 1000000
 
-	registerParser := func(name string) (Register, error) {
-		return name, nil
+// Runs all the commands of a program file on a CPU with traced hardware, returns the last word read by the program
+func runProgramFile(ctx context.Context, path string, maxSteps int, tracer TracerWithContextStack) (*Word, error) {
+	source, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read program file: %w", err)
 	}
 
-	interpreter := cpu.MakeProgramInterpreter(cpu.MakeSanitizedCommandInterpreter(cpu.MakeCommandInterpreter(cpu.MakeMicroCpuInterpreter(myCpu, registerParser))))
+	return cpu.RunProgram[Word, Float](ctx, string(source), settings, maxSteps, func(factories *MicroCpuFactories) {
+		traceFactories(factories, tracer)
+	})
+}
 
-	result, err := interpreter.Run(strings.Split(`
-		// hello world I guess...
+// Prints the error that stopped a program, with a diagnostic pointing to the offending line if available
+func printProgramError(output io.Writer, err error) {
+	var programError *cpu.ProgramError
+
+	if errors.As(err, &programError) {
+		fmt.Fprintf(output, "program stopped with errors:\n\n%v", programError.Diagnostic())
+	} else {
+		fmt.Fprintf(output, "program stopped with errors: %v\n", err)
+	}
+}
 
-		WW 1 w0 // write 1 into general purpose word register 0
-		RW w0   // read general purpose word register 0
-	`, "\n"))
+// Runs all the commands of a program file, returns the process exit code
+func runFile(ctx context.Context, path string, maxSteps int, tracer TracerWithContextStack) int {
+	result, err := runProgramFile(ctx, path, maxSteps, tracer)
 
 	if err != nil {
-		fmt.Printf("program stopped with errors: %v\n", err)
+		printProgramError(os.Stdout, err)
+		return 1
 	} else if result != nil {
 		fmt.Printf("program finished. result: %v\n", *result)
 	} else {
 		fmt.Println("program finished")
 	}
+
+	return 0
+}
+
+// Prints the recorded accesses to the memory address given as argument
+func memInfo(memoryLog cpu.MemoryAccessLog[Word], args []string) {
+	if memoryLog == nil {
+		fmt.Println("memory access log disabled, run with -memory-log <size> to enable it")
+		return
+	}
+
+	if len(args) != 1 {
+		fmt.Println("expected one address argument (e.g. .meminfo 0x10)")
+		return
+	}
+
+	address, err := strconv.ParseInt(args[0], 0, 32)
+
+	if err != nil {
+		fmt.Printf("could not parse address '%v': %v\n", args[0], err)
+		return
+	}
+
+	accesses := memoryLog.AccessesTo(Word(address))
+
+	if len(accesses) <= 0 {
+		fmt.Printf("no recorded accesses to address 0x%x\n", address)
+	}
+
+	for _, access := range accesses {
+		fmt.Println(&access)
+	}
+}
+
+// Runs commands read line by line from the input until EOF or .quit. memoryLog is the memory access log
+// queried by .meminfo, nil if disabled
+func repl(interpreter cpu.CommandInterpreter, memoryLog cpu.MemoryAccessLog[Word], input io.Reader) {
+	scanner := bufio.NewScanner(input)
+
+	fmt.Print(replPrompt)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		fields := strings.Fields(line)
+
+		switch {
+		case line == ".help":
+			printHelp()
+		case line == ".quit", line == ".exit":
+			return
+		case len(fields) > 0 && fields[0] == ".meminfo":
+			memInfo(memoryLog, fields[1:])
+		default:
+			if result, err := interpreter.Run(line); err != nil {
+				fmt.Printf("error: %v\n", err)
+			} else if result != nil {
+				fmt.Println(*result)
+			}
+		}
+
+		fmt.Print(replPrompt)
+	}
+}
+
+func run() int {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %v [flags] [program file]\n       %v run [-verbose] [-max-steps N] <program file>\n       %v encode <instruction>\n       %v decode <encoding>\n\nRuns a MicroCpu program file, or starts an interactive REPL if no file is given.\nrun runs a program quietly and exits with its result, for scripts.\nencode and decode check how a machine instruction is encoded and decoded.\n", os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+	tracerFormat := flag.String("tracer", "text", "hardware trace output format (text, json, none). JSON traces are written to stderr unless -trace-file is given")
+	traceOnly := flag.String("trace-only", "", "comma separated list of components to trace (e.g. \"main memory bus\"), all if empty")
+	traceExclude := flag.String("trace-exclude", "", "comma separated list of components to not trace")
+	traceMaxDepth := flag.Int("trace-max-depth", 0, "do not trace operations nested deeper than this depth, summarizing them as \"...\" (0 means unlimited)")
+	traceFile := flag.String("trace-file", "", "write traces into the given file instead of stdout/stderr")
+	traceFileSize := flag.Int64("trace-file-size", 0, "rotate the trace file when it grows past this size in bytes (0 disables rotation)")
+	traceFileCount := flag.Int("trace-file-count", 5, "number of rotated trace files to keep")
+	memoryLogSize := flag.Int("memory-log", 0, "record the last memory accesses of the REPL CPU and the commands that issued them, queried with .meminfo (0 disables the log, REPL only)")
+	maxSteps := flag.Int("max-steps", defaultMaxSteps, "stop programs that run more than this number of lines, e.g. because they loop forever (0 means unlimited)")
+	flag.Parse()
+
+	switch flag.Arg(0) {
+	case "encode":
+		return encodeCommand(flag.Args()[1:])
+	case "decode":
+		return decodeCommand(flag.Args()[1:])
+	case "run":
+		return runCommand(flag.Args()[1:])
+	}
+
+	if flag.NArg() > 0 && *memoryLogSize > 0 {
+		fmt.Println("-memory-log is only supported in the REPL, where .meminfo can query the log")
+		return 2
+	}
+
+	var tracer Tracer
+	var err error
+
+	if len(*traceFile) > 0 {
+		var fileTracer *FileTracer
+
+		fileTracer, err = MakeFileTracer(*traceFile, *traceFileSize, *traceFileCount, func(output io.Writer) (Tracer, error) {
+			return makeTracer(*tracerFormat, output)
+		})
+
+		if err == nil {
+			defer fileTracer.Close()
+			tracer = fileTracer
+		}
+	} else if *tracerFormat == "json" {
+		// keep the JSON stream apart from REPL prompts and program output so tools can consume it
+		tracer, err = makeTracer(*tracerFormat, os.Stderr)
+	} else {
+		tracer, err = makeTracer(*tracerFormat, os.Stdout)
+	}
+
+	if err != nil {
+		fmt.Println(err)
+		return 2
+	}
+
+	if len(*traceOnly) > 0 || len(*traceExclude) > 0 {
+		tracer = MakeFilteredTracer(tracer, splitList(*traceOnly), splitList(*traceExclude))
+	}
+
+	if *traceMaxDepth > 0 {
+		tracer = MakeDepthLimitedTracer(tracer, *traceMaxDepth, true)
+	}
+
+	tracerWithContext := MakeTracerWithContextStack(tracer)
+
+	if flag.NArg() > 0 {
+		// stop the program on Ctrl+C instead of killing the process, so deferred trace files are flushed
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		return runFile(ctx, flag.Arg(0), *maxSteps, tracerWithContext)
+	} else {
+		myCpu := makeCpu(tracerWithContext, *memoryLogSize)
+		interpreter := cpu.MakeSanitizedCommandInterpreter(cpu.MakeCommandInterpreter(cpu.MakeMicroCpuInterpreter(myCpu, cpu.ParseDefaultRegister)))
+		memoryLog, hasMemoryLog := myCpu.MemoryBus().(cpu.MemoryAccessLog[Word])
+
+		if hasMemoryLog {
+			interpreter = cpu.MakeMemoryAccessLogInterpreter[Word](interpreter, memoryLog)
+		}
+
+		repl(interpreter, memoryLog, os.Stdin)
+		return 0
+	}
+}
+
+func main() {
+	os.Exit(run())
 }
@@ -19,6 +19,7 @@ type MicroCpu[Register RegisterName, Word constraints.Integer, Float constraints
 	WordAlu() IntegerAlu[Register, Word]
 	FloatAlu() FloatAlu[Register, Float]
 	Memory() MemoryAccess[Register]
+	MemoryBus() MemoryBus[Word]
 }
 
 type MicroCpuFactory[Register RegisterName, Word constraints.Integer, Float constraints.Float] func(MicroCpuFactories[Register, Word, Float]) MicroCpu[Register, Word, Float]
@@ -182,3 +183,7 @@ func (m *microCpu[Register, Word, Float]) FloatAlu() FloatAlu[Register, Float] {
 func (m *microCpu[Register, Word, Float]) Memory() MemoryAccess[Register] {
 	return m.memoryAccess
 }
+
+func (m *microCpu[Register, Word, Float]) MemoryBus() MemoryBus[Word] {
+	return m.memoryBus
+}
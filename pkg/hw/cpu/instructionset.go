@@ -7,6 +7,11 @@ import (
 	"golang.org/x/exp/constraints"
 )
 
+var (
+	ErrIntegerOverflow = errors.New("integer overflow")
+	ErrDivisionByZero  = errors.New("division by zero")
+)
+
 func Zero[Type Number]() Type {
 	return 0
 }
@@ -52,10 +57,56 @@ type IntegerAlu[Register RegisterName, Type constraints.Integer] interface {
 
 type IntergerAluFactory[Register RegisterName, Type constraints.Integer] func(rs RegisterBank[Register, Type]) IntegerAlu[Register, Type]
 
+// IEEE 754 special value produced by a float operation
+type FloatException uint
+
+const (
+	// The result is a finite number
+	FloatException_None FloatException = iota
+	// The result is not a number
+	FloatException_NaN
+	// The result is positive infinity
+	FloatException_PositiveInfinity
+	// The result is negative infinity
+	FloatException_NegativeInfinity
+)
+
+func (e FloatException) String() string {
+	switch e {
+	case FloatException_None:
+		return "None"
+	case FloatException_NaN:
+		return "NaN"
+	case FloatException_PositiveInfinity:
+		return "+Inf"
+	case FloatException_NegativeInfinity:
+		return "-Inf"
+	}
+
+	panic("unreachable")
+}
+
+// Returns the exception corresponding to a float value
+func FloatExceptionOf[Type constraints.Float](value Type) FloatException {
+	switch {
+	case math.IsNaN(float64(value)):
+		return FloatException_NaN
+	case math.IsInf(float64(value), 1):
+		return FloatException_PositiveInfinity
+	case math.IsInf(float64(value), -1):
+		return FloatException_NegativeInfinity
+	}
+
+	return FloatException_None
+}
+
 type FloatAlu[Register RegisterName, Type constraints.Float] interface {
 	RingArithmeticUnit[Register, Type]
 	ComparisonUnit[Register, Type]
 
+	// Returns whether the result of the last operation written by the ALU was NaN or infinite
+	LastException() FloatException
+
 	Sin(src Register, dest Register) error
 	Cos(src Register, dest Register) error
 	Tan(src Register, dest Register) error
@@ -81,6 +132,13 @@ func (u *arithmeticUnit[Register, Type]) UnaryOp(src Register, dest Register, op
 }
 
 func (u *arithmeticUnit[Register, Type]) BinaryOp(lhs Register, rhs Register, dest Register, opBody func(Type, Type) Type) error {
+	return u.FallibleBinaryOp(lhs, rhs, dest, func(lhs Type, rhs Type) (Type, error) {
+		return opBody(lhs, rhs), nil
+	})
+}
+
+// Same as BinaryOp, but the operation can fail. The destination register is not written if the operation fails
+func (u *arithmeticUnit[Register, Type]) FallibleBinaryOp(lhs Register, rhs Register, dest Register, opBody func(Type, Type) (Type, error)) error {
 	lhsValue, lhsErr := u.rs.Read(lhs)
 	rhsValue, rhsErr := u.rs.Read(rhs)
 
@@ -88,7 +146,13 @@ func (u *arithmeticUnit[Register, Type]) BinaryOp(lhs Register, rhs Register, de
 		return errors.Join(lhsErr, rhsErr)
 	}
 
-	return u.rs.Write(opBody(lhsValue, rhsValue), dest)
+	result, err := opBody(lhsValue, rhsValue)
+
+	if err != nil {
+		return err
+	}
+
+	return u.rs.Write(result, dest)
 }
 
 type ringArithmeticUnit[Register RegisterName, Type Number] struct {
@@ -123,6 +187,85 @@ func (u *ringArithmeticUnit[Register, Type]) Div(lhs Register, rhs Register, des
 	})
 }
 
+// Returns true if the integer type is signed
+func isSigned[Type constraints.Integer]() bool {
+	return ^Type(0) < 0
+}
+
+// Returns -1 for signed integer types (all bits set)
+func minusOne[Type constraints.Integer]() Type {
+	return ^Type(0)
+}
+
+// Returns the minimum value of a signed integer type
+func minSigned[Type constraints.Integer]() Type {
+	return Type(1) << (Sizeof[Type]()*8 - 1)
+}
+
+// Ring arithmetic unit for integers that fails instead of wrapping around on overflow, and on divisions by zero
+type trappingRingArithmeticUnit[Register RegisterName, Type constraints.Integer] struct {
+	au arithmeticUnit[Register, Type]
+}
+
+func MakeTrappingRingArithmeticUnit[Register RegisterName, Type constraints.Integer](rs RegisterBank[Register, Type]) RingArithmeticUnit[Register, Type] {
+	return &trappingRingArithmeticUnit[Register, Type]{au: arithmeticUnit[Register, Type]{rs: rs}}
+}
+
+func (u *trappingRingArithmeticUnit[Register, Type]) Add(lhs Register, rhs Register, dest Register) error {
+	return u.au.FallibleBinaryOp(lhs, rhs, dest, func(lhs Type, rhs Type) (Type, error) {
+		result := lhs + rhs
+
+		if (rhs >= 0 && result < lhs) || (rhs < 0 && result > lhs) {
+			return 0, makeError(ErrIntegerOverflow, "%v + %v", lhs, rhs)
+		}
+
+		return result, nil
+	})
+}
+
+func (u *trappingRingArithmeticUnit[Register, Type]) Sub(lhs Register, rhs Register, dest Register) error {
+	return u.au.FallibleBinaryOp(lhs, rhs, dest, func(lhs Type, rhs Type) (Type, error) {
+		result := lhs - rhs
+
+		if (rhs >= 0 && result > lhs) || (rhs < 0 && result < lhs) {
+			return 0, makeError(ErrIntegerOverflow, "%v - %v", lhs, rhs)
+		}
+
+		return result, nil
+	})
+}
+
+func (u *trappingRingArithmeticUnit[Register, Type]) Mul(lhs Register, rhs Register, dest Register) error {
+	return u.au.FallibleBinaryOp(lhs, rhs, dest, func(lhs Type, rhs Type) (Type, error) {
+		result := lhs * rhs
+
+		// -1 * MIN wraps around to MIN, and so does MIN / -1, so the division check below misses it
+		if isSigned[Type]() && ((lhs == minusOne[Type]() && rhs == minSigned[Type]()) || (rhs == minusOne[Type]() && lhs == minSigned[Type]())) {
+			return 0, makeError(ErrIntegerOverflow, "%v * %v", lhs, rhs)
+		}
+
+		if lhs != 0 && result/lhs != rhs {
+			return 0, makeError(ErrIntegerOverflow, "%v * %v", lhs, rhs)
+		}
+
+		return result, nil
+	})
+}
+
+func (u *trappingRingArithmeticUnit[Register, Type]) Div(lhs Register, rhs Register, dest Register) error {
+	return u.au.FallibleBinaryOp(lhs, rhs, dest, func(lhs Type, rhs Type) (Type, error) {
+		if rhs == 0 {
+			return 0, makeError(ErrDivisionByZero, "%v / %v", lhs, rhs)
+		}
+
+		if isSigned[Type]() && lhs == minSigned[Type]() && rhs == minusOne[Type]() {
+			return 0, makeError(ErrIntegerOverflow, "%v / %v", lhs, rhs)
+		}
+
+		return lhs / rhs, nil
+	})
+}
+
 type bitOpsUnit[Register RegisterName, Type constraints.Integer] struct {
 	au arithmeticUnit[Register, Type]
 }
@@ -209,20 +352,65 @@ func MakeIntegerAlu[Register RegisterName, Type constraints.Integer](rs Register
 	}
 }
 
+// Makes an integer ALU that fails operations overflowing the range of the integer type with
+// ErrIntegerOverflow, and divisions by zero with ErrDivisionByZero. The destination register
+// is left untouched when an operation fails
+func MakeTrappingIntegerAlu[Register RegisterName, Type constraints.Integer](rs RegisterBank[Register, Type]) IntegerAlu[Register, Type] {
+	return &integerAlu[Register, Type]{
+		RingArithmeticUnit: MakeTrappingRingArithmeticUnit[Register, Type](rs),
+		BitOpsUnit:         MakeBitOptsUnit[Register, Type](rs),
+		ComparisonUnit:     MakeComparisonUnit[Register, Type](rs),
+	}
+}
+
+// Returns a factory of integer ALUs, trapping on overflow and division by zero if trapping is true
+// (See [MakeTrappingIntegerAlu])
+func MakeIntegerAluFactory[Register RegisterName, Type constraints.Integer](trapping bool) IntergerAluFactory[Register, Type] {
+	if trapping {
+		return MakeTrappingIntegerAlu[Register, Type]
+	}
+
+	return MakeIntegerAlu[Register, Type]
+}
+
+// Register bank that records the float exception of the last value written into it
+type floatExceptionRecorder[Register RegisterName, Type constraints.Float] struct {
+	RegisterBank[Register, Type]
+	lastException FloatException
+}
+
+func (r *floatExceptionRecorder[Register, Type]) Write(value Type, dest Register) error {
+	if err := r.RegisterBank.Write(value, dest); err != nil {
+		return err
+	}
+
+	// only results that were actually written count as the last result
+	r.lastException = FloatExceptionOf(value)
+	return nil
+}
+
 type floatAlu[Register RegisterName, Type constraints.Float] struct {
 	RingArithmeticUnit[Register, Type]
 	ComparisonUnit[Register, Type]
-	au arithmeticUnit[Register, Type]
+	au       arithmeticUnit[Register, Type]
+	recorder *floatExceptionRecorder[Register, Type]
 }
 
 func MakeFloatAlu[Register RegisterName, Type constraints.Float](rs RegisterBank[Register, Type]) FloatAlu[Register, Type] {
+	recorder := &floatExceptionRecorder[Register, Type]{RegisterBank: rs}
+
 	return &floatAlu[Register, Type]{
-		RingArithmeticUnit: MakeRingArithmeticUnit[Register, Type](rs),
-		ComparisonUnit:     MakeComparisonUnit[Register, Type](rs),
-		au:                 arithmeticUnit[Register, Type]{rs: rs},
+		RingArithmeticUnit: MakeRingArithmeticUnit[Register, Type](recorder),
+		ComparisonUnit:     MakeComparisonUnit[Register, Type](recorder),
+		au:                 arithmeticUnit[Register, Type]{rs: recorder},
+		recorder:           recorder,
 	}
 }
 
+func (u *floatAlu[Register, Type]) LastException() FloatException {
+	return u.recorder.lastException
+}
+
 func (u *floatAlu[Register, Type]) Sin(src Register, dest Register) error {
 	return u.au.UnaryOp(src, dest, func(src Type) Type {
 		return Type(math.Sin(float64(src)))
@@ -0,0 +1,156 @@
+package cpu
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTestAluRegisters[Type Number](lhs Type, rhs Type) RegisterBank[string, Type] {
+	rs := MakeRegisters[string, Type]("lhs", "rhs", "dest")
+	rs.Write(lhs, "lhs")
+	rs.Write(rhs, "rhs")
+	rs.Write(42, "dest")
+	return rs
+}
+
+func assertDest[Type Number](t *testing.T, rs RegisterBank[string, Type], expected Type) {
+	dest, err := rs.Read("dest")
+	assert.Nil(t, err)
+	assert.Equal(t, expected, dest)
+}
+
+func TestTrappingIntegerAlu_DivisionByZero(t *testing.T) {
+	rs := makeTestAluRegisters[int32](7, 0)
+	alu := MakeTrappingIntegerAlu[string, int32](rs)
+
+	assert.ErrorIs(t, alu.Div("lhs", "rhs", "dest"), ErrDivisionByZero)
+	assertDest(t, rs, 42)
+}
+
+func TestTrappingIntegerAlu_MinDividedByMinusOne(t *testing.T) {
+	rs := makeTestAluRegisters[int32](math.MinInt32, -1)
+	alu := MakeTrappingIntegerAlu[string, int32](rs)
+
+	assert.ErrorIs(t, alu.Div("lhs", "rhs", "dest"), ErrIntegerOverflow)
+	assertDest(t, rs, 42)
+
+	// non trapping ALUs wrap around
+	assert.Nil(t, MakeIntegerAlu[string, int32](rs).Div("lhs", "rhs", "dest"))
+	assertDest(t, rs, math.MinInt32)
+}
+
+func TestTrappingIntegerAlu_Overflow(t *testing.T) {
+	type op func(alu IntegerAlu[string, int32]) error
+
+	add := func(alu IntegerAlu[string, int32]) error { return alu.Add("lhs", "rhs", "dest") }
+	sub := func(alu IntegerAlu[string, int32]) error { return alu.Sub("lhs", "rhs", "dest") }
+	mul := func(alu IntegerAlu[string, int32]) error { return alu.Mul("lhs", "rhs", "dest") }
+
+	cases := []struct {
+		name     string
+		op       op
+		lhs      int32
+		rhs      int32
+		overflow bool
+		result   int32
+	}{
+		{"add", add, 1, 2, false, 3},
+		{"add overflow", add, math.MaxInt32, 1, true, 0},
+		{"add negative overflow", add, math.MinInt32, -1, true, 0},
+		{"sub", sub, 1, 2, false, -1},
+		{"sub overflow", sub, math.MinInt32, 1, true, 0},
+		{"sub negative overflow", sub, math.MaxInt32, -1, true, 0},
+		{"mul", mul, -3, 4, false, -12},
+		{"mul overflow", mul, math.MaxInt32, 2, true, 0},
+		{"mul min by minus one", mul, -1, math.MinInt32, true, 0},
+	}
+
+	for _, c := range cases {
+		rs := makeTestAluRegisters(c.lhs, c.rhs)
+		err := c.op(MakeTrappingIntegerAlu[string, int32](rs))
+
+		if c.overflow {
+			assert.ErrorIs(t, err, ErrIntegerOverflow, c.name)
+			assertDest(t, rs, 42)
+		} else {
+			assert.Nil(t, err, c.name)
+			assertDest(t, rs, c.result)
+		}
+	}
+}
+
+func TestTrappingIntegerAlu_Unsigned(t *testing.T) {
+	rs := makeTestAluRegisters[uint8](1, 2)
+	alu := MakeTrappingIntegerAlu[string, uint8](rs)
+
+	assert.ErrorIs(t, alu.Sub("lhs", "rhs", "dest"), ErrIntegerOverflow)
+	assert.Nil(t, alu.Add("lhs", "rhs", "dest"))
+	assertDest[uint8](t, rs, 3)
+
+	rs = makeTestAluRegisters[uint8](255, 1)
+	alu = MakeTrappingIntegerAlu[string, uint8](rs)
+
+	assert.ErrorIs(t, alu.Add("lhs", "rhs", "dest"), ErrIntegerOverflow)
+	assert.Nil(t, alu.Div("lhs", "rhs", "dest"))
+	assertDest[uint8](t, rs, 255)
+}
+
+func TestMakeIntegerAluFactory(t *testing.T) {
+	rs := makeTestAluRegisters[int32](math.MaxInt32, 1)
+
+	assert.ErrorIs(t, MakeIntegerAluFactory[string, int32](true)(rs).Add("lhs", "rhs", "dest"), ErrIntegerOverflow)
+	assert.Nil(t, MakeIntegerAluFactory[string, int32](false)(rs).Add("lhs", "rhs", "dest"))
+	assertDest(t, rs, math.MinInt32)
+}
+
+func TestFloatAlu_Exceptions(t *testing.T) {
+	type op func(alu FloatAlu[string, float32]) error
+
+	add := func(alu FloatAlu[string, float32]) error { return alu.Add("lhs", "rhs", "dest") }
+	mul := func(alu FloatAlu[string, float32]) error { return alu.Mul("lhs", "rhs", "dest") }
+	div := func(alu FloatAlu[string, float32]) error { return alu.Div("lhs", "rhs", "dest") }
+	equal := func(alu FloatAlu[string, float32]) error { return alu.Equal("lhs", "rhs", "dest") }
+
+	cases := []struct {
+		name      string
+		op        op
+		lhs       float32
+		rhs       float32
+		exception FloatException
+	}{
+		{"finite", add, 1, 2, FloatException_None},
+		{"zero divided by zero", div, 0, 0, FloatException_NaN},
+		{"overflow", mul, math.MaxFloat32, 2, FloatException_PositiveInfinity},
+		{"negative overflow", mul, -math.MaxFloat32, 2, FloatException_NegativeInfinity},
+		{"division by zero", div, -1, 0, FloatException_NegativeInfinity},
+		{"comparison", equal, float32(math.Inf(1)), float32(math.Inf(1)), FloatException_None},
+	}
+
+	for _, c := range cases {
+		alu := MakeFloatAlu[string, float32](makeTestAluRegisters(c.lhs, c.rhs))
+
+		assert.Nil(t, c.op(alu), c.name)
+		assert.Equal(t, c.exception, alu.LastException(), c.name)
+	}
+
+	// results that could not be written do not change the last exception
+	alu := MakeFloatAlu[string, float32](makeTestAluRegisters[float32](0, 0))
+
+	assert.Nil(t, alu.Add("lhs", "rhs", "dest"))
+	assert.ErrorIs(t, alu.Div("lhs", "rhs", "unknown"), ErrUnknownRegister)
+	assert.Equal(t, FloatException_None, alu.LastException())
+}
+
+func TestFloatAlu_ExceptionIsCleared(t *testing.T) {
+	rs := makeTestAluRegisters[float64](0, 0)
+	alu := MakeFloatAlu[string, float64](rs)
+
+	assert.Nil(t, alu.Div("lhs", "rhs", "dest"))
+	assert.Equal(t, FloatException_NaN, alu.LastException())
+
+	assert.Nil(t, rs.Write(1, "rhs"))
+	assert.Nil(t, alu.Div("lhs", "rhs", "dest"))
+	assert.Equal(t, FloatException_None, alu.LastException())
+}
@@ -1,10 +1,12 @@
 package cpu
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"golang.org/x/exp/constraints"
 )
@@ -19,6 +21,8 @@ type CommandInterpreter interface {
 
 type ProgramInterpreter interface {
 	Run(commands []string) (*string, error)
+	// Same as Run, but stops the program with [ErrInterrupted] once the context is done (e.g. on Ctrl+C)
+	RunContext(ctx context.Context, commands []string) (*string, error)
 }
 
 type commandInterpreter struct {
@@ -70,33 +74,322 @@ func MakeSanitizedCommandInterpreter(i CommandInterpreter) CommandInterpreter {
 }
 
 type programInterpreter struct {
-	impl CommandInterpreter
+	impl     CommandInterpreter
+	maxSteps int
 }
 
 func MakeProgramInterpreter(i CommandInterpreter) ProgramInterpreter {
+	return MakeProgramInterpreterWithStepLimit(i, 0)
+}
+
+// Returns a program interpreter that stops programs with [ErrStepLimitExceeded] once they run more than
+// maxSteps lines, so programs that loop forever terminate. Each line with an instruction (including jumps)
+// counts as one step. Zero means no limit
+func MakeProgramInterpreterWithStepLimit(i CommandInterpreter, maxSteps int) ProgramInterpreter {
 	return &programInterpreter{
-		impl: i,
+		impl:     i,
+		maxSteps: maxSteps,
+	}
+}
+
+// Error raised by an instruction argument. Index 0 refers to the instruction itself, and
+// indices from 1 onwards to its arguments
+type ArgumentError struct {
+	Index int
+	Err   error
+}
+
+func (e *ArgumentError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ArgumentError) Unwrap() error {
+	return e.Err
+}
+
+// Returns the column (starting from 1) of the n-th whitespace separated field of a command, ignoring
+// comments. If there are less fields than requested, returns the column right after the last one
+func fieldColumn(command string, n int) int {
+	command, _, _ = strings.Cut(command, "//")
+	column := 0
+	inField := false
+
+	for i, char := range command {
+		if unicode.IsSpace(char) {
+			inField = false
+		} else if !inField {
+			if n == 0 {
+				return i + 1
+			}
+
+			inField = true
+			n--
+		}
+
+		if !unicode.IsSpace(char) {
+			column = i + 1
+		}
 	}
+
+	return column + 1
+}
+
+// Error raised while running a program, pointing to the offending line of the program
+type ProgramError struct {
+	// Line number, starting from 1
+	Line int
+	// Column of the offending instruction or argument, starting from 1. Zero if unknown
+	Column int
+	// Text of the offending line
+	Text string
+	// Error raised by the line
+	Err error
 }
 
+func (e *ProgramError) Error() string {
+	return fmt.Sprintf("error at line %v (%v): %v", e.Line, strings.TrimSpace(e.Text), e.Err)
+}
+
+func (e *ProgramError) Unwrap() error {
+	return e.Err
+}
+
+// Returns a multiline diagnostic showing the offending line with a caret under the offending
+// instruction or argument
+func (e *ProgramError) Diagnostic() string {
+	var builder strings.Builder
+	gutter := fmt.Sprintf(" %v | ", e.Line)
+
+	builder.WriteString(fmt.Sprintf("error at line %v: %v\n", e.Line, e.Err))
+	builder.WriteString(gutter)
+	builder.WriteString(e.Text)
+	builder.WriteString("\n")
+
+	if e.Column > 0 {
+		builder.WriteString(strings.Repeat(" ", len(gutter)-2))
+		builder.WriteString("| ")
+
+		// keep tabs so the caret is aligned with the line text
+		for _, char := range e.Text[:min(e.Column-1, len(e.Text))] {
+			if char == '\t' {
+				builder.WriteRune(char)
+			} else {
+				builder.WriteRune(' ')
+			}
+		}
+
+		builder.WriteString("^\n")
+	}
+
+	return builder.String()
+}
+
+// Returns a [ProgramError] for the given line, starting from 1. If the error was raised by an instruction
+// argument (See [ArgumentError]), the error column points to that argument
 func MakeProgramError(line int, command string, err error) error {
-	return fmt.Errorf("error at line %v (%v): %w", line, command, err)
+	return newProgramError(line, command, command, err)
+}
+
+// Same as [MakeProgramError], but locating the offending argument in the given command instead of the line text
+func newProgramError(line int, text string, command string, err error) *ProgramError {
+	programError := &ProgramError{
+		Line: line,
+		Text: text,
+		Err:  err,
+	}
+
+	var argumentError *ArgumentError
+
+	if errors.As(err, &argumentError) {
+		programError.Column = fieldColumn(command, argumentError.Index)
+	}
+
+	return programError
 }
 
 func (i *programInterpreter) Run(commands []string) (*string, error) {
+	return i.RunContext(context.Background(), commands)
+}
+
+func (i *programInterpreter) RunContext(ctx context.Context, commands []string) (*string, error) {
+	labels, lines, err := parseLabels(commands)
+
+	if err != nil {
+		return nil, err
+	}
+
 	var lastResult *string
+	steps := 0
+
+	for line := 0; line < len(lines); {
+		command := lines[line]
+
+		// lines with just a label definition have no command to run
+		if len(command) <= 0 && len(commands[line]) > 0 {
+			line++
+			continue
+		}
+
+		if code, _, _ := strings.Cut(command, "//"); len(strings.TrimSpace(code)) > 0 {
+			steps++
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, newProgramError(line+1, commands[line], command, makeError(ErrInterrupted, "%v", err))
+		}
+
+		if i.maxSteps > 0 && steps > i.maxSteps {
+			return nil, newProgramError(line+1, commands[line], command, makeError(ErrStepLimitExceeded, "program did not finish after running %v steps", i.maxSteps))
+		}
+
+		if target, isJump, err := jump(command, labels, lastResult); err != nil {
+			return nil, newProgramError(line+1, commands[line], command, err)
+		} else if isJump {
+			if target >= 0 {
+				line = target
+			} else {
+				line++
+			}
+
+			continue
+		}
 
-	for line, command := range commands {
 		if result, err := i.impl.Run(command); err != nil {
-			return nil, MakeProgramError(line, command, err)
+			return nil, newProgramError(line+1, commands[line], command, err)
 		} else if result != nil {
 			lastResult = result
 		}
+
+		line++
 	}
 
 	return lastResult, nil
 }
 
+const (
+	ProgramInstruction_Jump          string = "JMP"
+	ProgramInstruction_JumpIfZero    string = "JZ"
+	ProgramInstruction_JumpIfNotZero string = "JNZ"
+)
+
+// All instructions handled by the program interpreter itself, in addition to the instructions
+// of the interpreter running the program commands
+var ProgramInstructions = []InstructionInfo{
+	{
+		Mnemonic:    ProgramInstruction_Jump,
+		Arguments:   "<label>",
+		Description: "continue running the program from the given label",
+	},
+	{
+		Mnemonic:    ProgramInstruction_JumpIfZero,
+		Arguments:   "<label>",
+		Description: "jump to the given label if the last value read with RW was 0",
+	},
+	{
+		Mnemonic:    ProgramInstruction_JumpIfNotZero,
+		Arguments:   "<label>",
+		Description: "jump to the given label if the last value read with RW was not 0",
+	},
+}
+
+func isLabelName(name string) bool {
+	for i, char := range name {
+		if !(char == '_' || unicode.IsLetter(char) || (i > 0 && unicode.IsDigit(char))) {
+			return false
+		}
+	}
+
+	return len(name) > 0
+}
+
+// Splits a label definition ("name:") from the beginning of a program line. Returns the label
+// name (empty if the line has no label) and the line with the label definition replaced by
+// whitespace, so columns of the rest of the line are preserved
+func splitLabel(line string) (string, string) {
+	fields := strings.Fields(line)
+
+	if len(fields) <= 0 || !strings.HasSuffix(fields[0], ":") || !isLabelName(strings.TrimSuffix(fields[0], ":")) {
+		return "", line
+	}
+
+	begin := strings.Index(line, fields[0])
+	end := begin + len(fields[0])
+
+	return strings.TrimSuffix(fields[0], ":"), line[:begin] + strings.Repeat(" ", end-begin) + line[end:]
+}
+
+// Collects all label definitions of a program. Returns the line index of each label and the
+// program lines without label definitions. Lines left with no command after removing the label
+// definition are returned empty
+func parseLabels(commands []string) (map[string]int, []string, error) {
+	labels := make(map[string]int)
+	lines := make([]string, len(commands))
+
+	for line, command := range commands {
+		label, rest := splitLabel(command)
+		lines[line] = rest
+
+		if len(label) <= 0 {
+			continue
+		}
+
+		if definition, isDefined := labels[label]; isDefined {
+			return nil, nil, newProgramError(line+1, command, command, &ArgumentError{Index: 0, Err: makeError(ErrDuplicatedLabel, "'%v' already defined at line %v", label, definition+1)})
+		}
+
+		labels[label] = line
+
+		if code, _, _ := strings.Cut(rest, "//"); len(strings.TrimSpace(code)) <= 0 {
+			lines[line] = ""
+		}
+	}
+
+	return labels, lines, nil
+}
+
+// Runs the program level jump instructions. Conditional jumps test lastResult, the last result returned
+// by a program command (i.e. the last value read with RW), no matter how many commands ran after it.
+// Returns whether the command was a jump instruction and the index of the line to continue from, or -1
+// if the jump was not taken
+func jump(command string, labels map[string]int, lastResult *string) (int, bool, error) {
+	code, _, _ := strings.Cut(command, "//")
+	fields := strings.Fields(code)
+
+	if len(fields) <= 0 {
+		return -1, false, nil
+	}
+
+	switch fields[0] {
+	case ProgramInstruction_Jump, ProgramInstruction_JumpIfZero, ProgramInstruction_JumpIfNotZero:
+	default:
+		return -1, false, nil
+	}
+
+	if err := checkArgumentCount(fields[1:], 1, "one label argument"); err != nil {
+		return -1, true, err
+	}
+
+	target, isDefined := labels[fields[1]]
+
+	if !isDefined {
+		return -1, true, &ArgumentError{Index: 1, Err: makeError(ErrUndefinedLabel, "'%v'", fields[1])}
+	}
+
+	if fields[0] == ProgramInstruction_Jump {
+		return target, true, nil
+	}
+
+	if lastResult == nil {
+		return -1, true, &ArgumentError{Index: 0, Err: MakeInterpreterError(ErrBadInstruction, "conditional jump with no value read with RW before to test")}
+	}
+
+	if isZero := *lastResult == "0"; isZero == (fields[0] == ProgramInstruction_JumpIfZero) {
+		return target, true, nil
+	}
+
+	return -1, true, nil
+}
+
 type microCpuInterpreter[Register RegisterName, Word constraints.Integer, Float constraints.Float] struct {
 	MicroCpu[Register, Word, Float]
 	registerParser RegisterParser[Register]
@@ -123,27 +416,81 @@ func MakeInterpreterError(err error, args ...any) error {
 }
 
 var (
-	ErrInterpreter    = errors.New("interpreter error")
-	ErrBadParameters  = errors.New("bad paramters")
-	ErrBadInstruction = errors.New("bad instruction")
+	ErrInterpreter       = errors.New("interpreter error")
+	ErrBadParameters     = errors.New("bad paramters")
+	ErrBadInstruction    = errors.New("bad instruction")
+	ErrUndefinedLabel    = errors.New("undefined label")
+	ErrDuplicatedLabel   = errors.New("duplicated label")
+	ErrStepLimitExceeded = errors.New("step limit exceeded")
+	ErrInterrupted       = errors.New("program interrupted")
 )
 
 const (
 	MicroCpuInstruction_ReadWord  string = "RW"
 	MicroCpuInstruction_WriteWord string = "WW"
+	MicroCpuInstruction_Load      string = "LD"
+	MicroCpuInstruction_Store     string = "ST"
 )
 
+// Contains usage information about an interpreter instruction
+type InstructionInfo struct {
+	// Instruction mnemonic, as written in programs
+	Mnemonic string
+	// Instruction arguments
+	Arguments string
+	// Instruction description (for documentation and help messages)
+	Description string
+}
+
+// All instructions supported by the MicroCpu interpreter
+var MicroCpuInstructions = []InstructionInfo{
+	{
+		Mnemonic:    MicroCpuInstruction_ReadWord,
+		Arguments:   "<register>",
+		Description: "read the value of a word register",
+	},
+	{
+		Mnemonic:    MicroCpuInstruction_WriteWord,
+		Arguments:   "<value> <register>",
+		Description: "write a decimal value into a word register",
+	},
+	{
+		Mnemonic:    MicroCpuInstruction_Load,
+		Arguments:   "<address register> <register>",
+		Description: "load the memory word at the address held by the first register into the second register",
+	},
+	{
+		Mnemonic:    MicroCpuInstruction_Store,
+		Arguments:   "<register> <address register>",
+		Description: "store the value of the first register into memory at the address held by the second register",
+	},
+}
+
+// Returns an error if the instruction was not given the expected number of arguments, pointing to the first
+// missing or extra argument
+func checkArgumentCount(args []string, expected int, description string) error {
+	if len(args) == expected {
+		return nil
+	}
+
+	return &ArgumentError{
+		Index: min(len(args), expected) + 1,
+		Err:   MakeInterpreterError(ErrBadParameters, "expected %v, got %v arguments", description, len(args)),
+	}
+}
+
 func (i *microCpuInterpreter[Register, Word, Float]) readWord(args ...string) (*string, error) {
-	if len(args) > 1 {
-		return nil, MakeInterpreterError(ErrBadParameters, "expected one register argument, got %v arguments", len(args))
+	if err := checkArgumentCount(args, 1, "one register argument"); err != nil {
+		return nil, err
 	}
 
 	if r, err := i.registerParser(args[0]); err != nil {
-		return nil, MakeInterpreterError(ErrBadParameters, "could not parse register argument '%v': %w", args[0], err)
+		return nil, &ArgumentError{Index: 1, Err: MakeInterpreterError(ErrBadParameters, "could not parse register argument '%v': %w", args[0], err)}
+	} else if value, err := i.AllWordRegisters().Read(r); err != nil {
+		return nil, &ArgumentError{Index: 1, Err: err}
 	} else {
-		value, err := i.AllWordRegisters().Read(r)
 		strValue := fmt.Sprint(value)
-		return &strValue, err
+		return &strValue, nil
 	}
 }
 
@@ -156,20 +503,63 @@ func parseWord[Word constraints.Integer](str string) (Word, error) {
 }
 
 func (i *microCpuInterpreter[Register, Word, Float]) writeWord(args ...string) error {
-	if len(args) > 2 {
-		return MakeInterpreterError(ErrBadParameters, "expected one word argument and one register argument, got %v arguments", len(args))
+	if err := checkArgumentCount(args, 2, "one word argument and one register argument"); err != nil {
+		return err
 	}
 
 	value, err := parseWord[Word](args[0])
 	if err != nil {
-		return err
+		return &ArgumentError{Index: 1, Err: err}
 	}
 
 	if r, err := i.registerParser(args[1]); err != nil {
-		return MakeInterpreterError(ErrBadParameters, "could not parse register argument '%v': %w", args[1], err)
-	} else {
-		return i.AllWordRegisters().Write(value, r)
+		return &ArgumentError{Index: 2, Err: MakeInterpreterError(ErrBadParameters, "could not parse register argument '%v': %w", args[1], err)}
+	} else if err := i.AllWordRegisters().Write(value, r); err != nil {
+		return &ArgumentError{Index: 2, Err: err}
+	}
+
+	return nil
+}
+
+// Parses the two register arguments of a memory access instruction
+func (i *microCpuInterpreter[Register, Word, Float]) parseMemoryAccessRegisters(args ...string) (Register, Register, error) {
+	var registers [2]Register
+
+	if err := checkArgumentCount(args, 2, "two register arguments"); err != nil {
+		return registers[0], registers[1], err
+	}
+
+	for index, arg := range args {
+		r, err := i.registerParser(arg)
+
+		if err != nil {
+			return registers[0], registers[1], &ArgumentError{Index: index + 1, Err: MakeInterpreterError(ErrBadParameters, "could not parse register argument '%v': %w", arg, err)}
+		}
+
+		registers[index] = r
+	}
+
+	return registers[0], registers[1], nil
+}
+
+func (i *microCpuInterpreter[Register, Word, Float]) load(args ...string) error {
+	address, dest, err := i.parseMemoryAccessRegisters(args...)
+
+	if err != nil {
+		return err
 	}
+
+	return i.Memory().Load(address, dest)
+}
+
+func (i *microCpuInterpreter[Register, Word, Float]) store(args ...string) error {
+	src, address, err := i.parseMemoryAccessRegisters(args...)
+
+	if err != nil {
+		return err
+	}
+
+	return i.Memory().Store(src, address)
 }
 
 func (i *microCpuInterpreter[Register, Word, Float]) Run(instruction string, args ...string) (*string, error) {
@@ -178,7 +568,11 @@ func (i *microCpuInterpreter[Register, Word, Float]) Run(instruction string, arg
 		return i.readWord(args...)
 	case MicroCpuInstruction_WriteWord:
 		return nil, i.writeWord(args...)
+	case MicroCpuInstruction_Load:
+		return nil, i.load(args...)
+	case MicroCpuInstruction_Store:
+		return nil, i.store(args...)
 	}
 
-	return nil, MakeInterpreterError(ErrBadInstruction, "unsupported instruction '%v'", instruction)
+	return nil, &ArgumentError{Index: 0, Err: MakeInterpreterError(ErrBadInstruction, "unsupported instruction '%v'", instruction)}
 }
@@ -0,0 +1,218 @@
+package cpu
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Returns the command interpreter programs of the given CPU run with
+func makeTestCommandInterpreter(microCpu MicroCpu[string, int32, float32]) CommandInterpreter {
+	return MakeSanitizedCommandInterpreter(MakeCommandInterpreter(MakeMicroCpuInterpreter(microCpu, ParseDefaultRegister)))
+}
+
+func makeTestProgramInterpreterWithStepLimit(maxSteps int) ProgramInterpreter {
+	microCpu := MakeMicroCpu[string, int32, float32](testSettings, MakeDefaultMicroCpuFactories[int32, float32](testSettings))
+	return MakeProgramInterpreterWithStepLimit(makeTestCommandInterpreter(microCpu), maxSteps)
+}
+
+func makeTestProgramInterpreter() ProgramInterpreter {
+	return makeTestProgramInterpreterWithStepLimit(0)
+}
+
+func runTestProgram(t *testing.T, program ...string) *ProgramError {
+	_, err := makeTestProgramInterpreter().Run(program)
+
+	var programError *ProgramError
+	require.True(t, errors.As(err, &programError), "expected a program error, got %v", err)
+	return programError
+}
+
+func TestProgramError_BadArgument(t *testing.T) {
+	err := runTestProgram(t,
+		"// comment",
+		"WW 1 w0",
+		"WW x w0 // bad word")
+
+	assert.Equal(t, 3, err.Line)
+	assert.Equal(t, 4, err.Column)
+	assert.Equal(t, "WW x w0 // bad word", err.Text)
+	assert.ErrorIs(t, err, ErrBadParameters)
+	assert.Equal(t, ""+
+		"error at line 3: interpreter error: bad paramters: strconv.ParseInt: parsing \"x\": invalid syntax\n"+
+		" 3 | WW x w0 // bad word\n"+
+		"   |    ^\n",
+		err.Diagnostic())
+}
+
+func TestProgramError_BadInstruction(t *testing.T) {
+	err := runTestProgram(t,
+		"WW 1 w0",
+		"",
+		"\tFOO w0")
+
+	assert.Equal(t, 3, err.Line)
+	assert.Equal(t, 2, err.Column)
+	assert.ErrorIs(t, err, ErrBadInstruction)
+	assert.Equal(t, ""+
+		"error at line 3: interpreter error: bad instruction: unsupported instruction 'FOO'\n"+
+		" 3 | \tFOO w0\n"+
+		"   | \t^\n",
+		err.Diagnostic())
+}
+
+func TestProgramError_MissingArgument(t *testing.T) {
+	err := runTestProgram(t, "RW")
+
+	assert.Equal(t, 1, err.Line)
+	assert.Equal(t, 3, err.Column)
+	assert.ErrorIs(t, err, ErrBadParameters)
+}
+
+func TestProgramError_ExtraArgument(t *testing.T) {
+	err := runTestProgram(t, "RW w0 w1")
+
+	assert.Equal(t, 7, err.Column)
+	assert.ErrorIs(t, err, ErrBadParameters)
+}
+
+func TestProgramError_UnknownRegister(t *testing.T) {
+	err := runTestProgram(t, "WW 1 w0", "WW 1 w42")
+
+	assert.Equal(t, 2, err.Line)
+	assert.Equal(t, 6, err.Column)
+	assert.ErrorIs(t, err, ErrUnknownRegister)
+}
+
+func runTestProgramResult(t *testing.T, program ...string) string {
+	result, err := makeTestProgramInterpreter().Run(program)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, result)
+
+	if result == nil {
+		return ""
+	}
+
+	return *result
+}
+
+func TestProgramLabels_Jump(t *testing.T) {
+	assert.Equal(t, "2", runTestProgramResult(t,
+		"WW 1 w0",
+		"JMP end // skip next write",
+		"WW 3 w0",
+		"end:",
+		"WW 2 w0",
+		"RW w0"))
+}
+
+func TestProgramLabels_LabelOnCommandLine(t *testing.T) {
+	assert.Equal(t, "3", runTestProgramResult(t,
+		"JMP write",
+		"WW 1 w0",
+		"write: WW 3 w0",
+		"RW w0"))
+}
+
+func TestProgramLabels_ConditionalJumps(t *testing.T) {
+	assert.Equal(t, "1", runTestProgramResult(t,
+		"WW 0 w0",
+		"RW w0",
+		"JNZ fail",
+		"JZ ok",
+		"fail:",
+		"WW 2 w1",
+		"JMP end",
+		"ok:",
+		"WW 1 w1",
+		"end:",
+		"RW w1"))
+}
+
+func TestProgramLabels_BackwardJump(t *testing.T) {
+	assert.Equal(t, "7", runTestProgramResult(t,
+		"JMP start",
+		"loop:",
+		"WW 7 w0",
+		"RW w0",
+		"JNZ end",
+		"start:",
+		"WW 0 w0",
+		"RW w0",
+		"JZ loop",
+		"end:"))
+}
+
+func TestProgramLabels_ConditionalJumpTestsLastReadValue(t *testing.T) {
+	// WW does not read any value, so JZ tests the 5 read from w0 and does not jump
+	assert.Equal(t, "1", runTestProgramResult(t,
+		"WW 5 w0",
+		"RW w0",
+		"WW 0 w1",
+		"JZ zero",
+		"WW 1 w0",
+		"zero:",
+		"RW w0"))
+}
+
+func TestProgramLabels_UndefinedLabel(t *testing.T) {
+	err := runTestProgram(t, "WW 1 w0", "JMP nowhere")
+
+	assert.Equal(t, 2, err.Line)
+	assert.Equal(t, 5, err.Column)
+	assert.ErrorIs(t, err, ErrUndefinedLabel)
+}
+
+func TestProgramLabels_DuplicatedLabel(t *testing.T) {
+	err := runTestProgram(t, "start:", "WW 1 w0", "  start: RW w0")
+
+	assert.Equal(t, 3, err.Line)
+	assert.Equal(t, 3, err.Column)
+	assert.ErrorIs(t, err, ErrDuplicatedLabel)
+}
+
+func TestProgramLabels_ConditionalJumpWithoutResult(t *testing.T) {
+	err := runTestProgram(t, "end:", "JZ end")
+
+	assert.Equal(t, 2, err.Line)
+	assert.ErrorIs(t, err, ErrBadInstruction)
+}
+
+func TestProgramLabels_ErrorColumnAfterLabel(t *testing.T) {
+	err := runTestProgram(t, "label: WW x w0")
+
+	assert.Equal(t, 11, err.Column)
+	assert.Equal(t, "label: WW x w0", err.Text)
+}
+
+func TestProgramStepLimit_InfiniteLoop(t *testing.T) {
+	_, err := makeTestProgramInterpreterWithStepLimit(100).Run([]string{"loop:", "  JMP loop"})
+
+	var programError *ProgramError
+	require.True(t, errors.As(err, &programError), "expected a program error, got %v", err)
+	assert.ErrorIs(t, err, ErrStepLimitExceeded)
+	assert.Equal(t, 2, programError.Line)
+}
+
+func TestProgramStepLimit_ProgramWithinLimit(t *testing.T) {
+	// label-only lines, empty lines and comments do not count as steps
+	result, err := makeTestProgramInterpreterWithStepLimit(3).Run([]string{
+		"start:",
+		"WW 7 w0",
+		"",
+		"// comment",
+		"JMP end",
+		"end:",
+		"RW w0",
+	})
+
+	assert.Nil(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, "7", *result)
+
+	_, err = makeTestProgramInterpreterWithStepLimit(2).Run([]string{"WW 7 w0", "JMP end", "end:", "RW w0"})
+	assert.ErrorIs(t, err, ErrStepLimitExceeded)
+}
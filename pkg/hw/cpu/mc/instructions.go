@@ -3,7 +3,9 @@ package mc
 import (
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/Manu343726/cucaracha/pkg/utils"
 )
@@ -269,3 +271,54 @@ func (d *InstructionsDescriptor) Decode(binaryRepresentation uint32) (*Instructi
 func DecodeInstruction(binaryRepresentation uint32) (*Instruction, error) {
 	return Descriptor_Instructions.Decode(binaryRepresentation)
 }
+
+var ErrInvalidInstruction = errors.New("invalid instruction")
+
+// Parses an instruction from its assembly representation, with operands separated by commas
+// or spaces (See [Instruction.String]). Operand values can be written in decimal, hex (0x),
+// octal (0o) or binary (0b)
+func (d *InstructionsDescriptor) Parse(text string) (*Instruction, error) {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+
+	if len(fields) <= 0 {
+		return nil, utils.MakeError(ErrInvalidInstruction, "empty instruction")
+	}
+
+	opCode, err := Descriptor_Opcodes.ParseOpCode(fields[0])
+
+	if err != nil {
+		return nil, err
+	}
+
+	descriptor, err := d.Instruction(opCode)
+
+	if err != nil {
+		return nil, err
+	}
+
+	operands := fields[1:]
+
+	if len(operands) != len(descriptor.Operands) {
+		return nil, utils.MakeError(ErrInvalidInstruction, "'%v' expects %v operands, got %v", descriptor.OpCode.Mnemonic, len(descriptor.Operands), len(operands))
+	}
+
+	operandValues := make([]uint64, len(operands))
+
+	for i, operand := range operands {
+		if operandValues[i], err = strconv.ParseUint(operand, 0, 64); err != nil {
+			return nil, utils.MakeError(ErrInvalidInstruction, "could not parse operand [%v] %v '%v': %w", i, descriptor.Operands[i], operand, err)
+		}
+	}
+
+	return &Instruction{
+		Descriptor:    descriptor,
+		OperandValues: operandValues,
+	}, nil
+}
+
+// Parses an instruction from its assembly representation
+func ParseInstruction(text string) (*Instruction, error) {
+	return Descriptor_Instructions.Parse(text)
+}
@@ -21,3 +21,15 @@ func TestAddFactory(t *testing.T) {
 	assert.NotNil(t, decodedInstr)
 	assert.Equal(t, instr, decodedInstr)
 }
+
+func TestAddParse(t *testing.T) {
+	instr := Add(1, 2, 3)
+
+	parsedInstr, err := mc.ParseInstruction(instr.String())
+
+	assert.Nil(t, err)
+	assert.Equal(t, instr, parsedInstr)
+
+	_, err = mc.ParseInstruction("ADD 1, 2")
+	assert.ErrorIs(t, err, mc.ErrInvalidInstruction)
+}
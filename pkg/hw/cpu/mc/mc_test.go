@@ -9,3 +9,10 @@ import (
 func TestDocumentation(t *testing.T) {
 	assert.Equal(t, ``, Descriptor.Documentation(0))
 }
+
+func TestDecodeOpCode_Invalid(t *testing.T) {
+	_, err := Descriptor.OpCodes.DecodeOpCode(uint64(TOTAL_OPCODES))
+
+	assert.ErrorIs(t, err, ErrInvalidOpCode)
+	assert.NotContains(t, err.Error(), "%!", "all format verbs must have arguments")
+}
@@ -83,7 +83,7 @@ func (d *OpCodesDescriptor) DecodeOpCode(binaryRepresentation uint64) (OpCode, e
 	opCode := OpCode(binaryRepresentation)
 
 	if opCode >= TOTAL_OPCODES {
-		return 0, utils.MakeError(ErrInvalidOpCode, "%v (hex: %v, bin: %v)", utils.FormatUintBinary(binaryRepresentation, 64), utils.FormatUintHex(binaryRepresentation, 16))
+		return 0, utils.MakeError(ErrInvalidOpCode, "%v (hex: %v, bin: %v)", binaryRepresentation, utils.FormatUintHex(binaryRepresentation, (d.OpCodeBits()+3)/4), utils.FormatUintBinary(binaryRepresentation, d.OpCodeBits()))
 	}
 
 	return opCode, nil
@@ -0,0 +1,180 @@
+package cpu
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/constraints"
+)
+
+type MemoryAccessKind uint
+
+const (
+	MemoryAccessKind_Read MemoryAccessKind = iota
+	MemoryAccessKind_Write
+)
+
+func (k MemoryAccessKind) String() string {
+	switch k {
+	case MemoryAccessKind_Read:
+		return "Read"
+	case MemoryAccessKind_Write:
+		return "Write"
+	}
+
+	panic("unreachable")
+}
+
+// Describes a memory access done through a memory bus
+type MemoryAccessRecord[Word constraints.Integer] struct {
+	// Position of the access within all the accesses done through the bus, starting from 0
+	Sequence uint64
+	// Whether the access was a read or a write
+	Kind MemoryAccessKind
+	// Accessed address
+	Address Word
+	// Value read or written
+	Value Word
+	// Error returned by the bus, if any
+	Error error
+	// Instruction that issued the access (See [MemoryAccessLog.SetInstruction]), empty if unknown
+	Instruction string
+}
+
+func (r *MemoryAccessRecord[Word]) String() string {
+	var access string
+
+	if r.Error != nil {
+		access = fmt.Sprintf("#%v %v 0x%x error: %v", r.Sequence, r.Kind, r.Address, r.Error)
+	} else {
+		access = fmt.Sprintf("#%v %v 0x%x value: %v", r.Sequence, r.Kind, r.Address, r.Value)
+	}
+
+	if len(r.Instruction) > 0 {
+		return fmt.Sprintf("%v by '%v'", access, r.Instruction)
+	}
+
+	return access
+}
+
+// Memory bus that records the last accesses done through it
+type MemoryAccessLog[Word constraints.Integer] interface {
+	MemoryBus[Word]
+
+	// Returns all recorded accesses, oldest first
+	Accesses() []MemoryAccessRecord[Word]
+	// Returns the recorded accesses to the given address, oldest first
+	AccessesTo(address Word) []MemoryAccessRecord[Word]
+	// Sets the instruction recorded with the following accesses, so the log tells which instructions
+	// touched each address. Usually set through [MakeMemoryAccessLogInterpreter]
+	SetInstruction(instruction string)
+}
+
+type memoryAccessLog[Word constraints.Integer] struct {
+	MemoryBus[Word]
+	records     []MemoryAccessRecord[Word]
+	next        uint64
+	instruction string
+}
+
+// Wraps a memory bus recording its last accesses into a ring buffer of the given capacity
+func MakeMemoryAccessLog[Word constraints.Integer](impl MemoryBus[Word], capacity int) MemoryAccessLog[Word] {
+	if capacity <= 0 {
+		panic("memory access log capacity must be greater than zero")
+	}
+
+	return &memoryAccessLog[Word]{
+		MemoryBus: impl,
+		records:   make([]MemoryAccessRecord[Word], 0, capacity),
+	}
+}
+
+func MemoryAccessLogFactory[Word constraints.Integer](factory MemoryBusFactory[Word], capacity int) MemoryBusFactory[Word] {
+	return func() MemoryBus[Word] {
+		return MakeMemoryAccessLog[Word](factory(), capacity)
+	}
+}
+
+func (l *memoryAccessLog[Word]) record(kind MemoryAccessKind, address Word, value Word, err error) {
+	record := MemoryAccessRecord[Word]{
+		Sequence:    l.next,
+		Kind:        kind,
+		Address:     address,
+		Value:       value,
+		Error:       err,
+		Instruction: l.instruction,
+	}
+
+	if len(l.records) < cap(l.records) {
+		l.records = append(l.records, record)
+	} else {
+		l.records[l.next%uint64(cap(l.records))] = record
+	}
+
+	l.next++
+}
+
+func (l *memoryAccessLog[Word]) Read(address Word) (Word, error) {
+	value, err := l.MemoryBus.Read(address)
+	l.record(MemoryAccessKind_Read, address, value, err)
+	return value, err
+}
+
+func (l *memoryAccessLog[Word]) Write(value Word, address Word) error {
+	err := l.MemoryBus.Write(value, address)
+	l.record(MemoryAccessKind_Write, address, value, err)
+	return err
+}
+
+func (l *memoryAccessLog[Word]) Accesses() []MemoryAccessRecord[Word] {
+	accesses := make([]MemoryAccessRecord[Word], 0, len(l.records))
+
+	// once the buffer is full, the oldest record is the one that will be overwritten next
+	oldest := 0
+	if len(l.records) == cap(l.records) {
+		oldest = int(l.next % uint64(cap(l.records)))
+	}
+
+	for i := range l.records {
+		accesses = append(accesses, l.records[(oldest+i)%len(l.records)])
+	}
+
+	return accesses
+}
+
+func (l *memoryAccessLog[Word]) AccessesTo(address Word) []MemoryAccessRecord[Word] {
+	accesses := make([]MemoryAccessRecord[Word], 0)
+
+	for _, access := range l.Accesses() {
+		if access.Address == address {
+			accesses = append(accesses, access)
+		}
+	}
+
+	return accesses
+}
+
+func (l *memoryAccessLog[Word]) SetInstruction(instruction string) {
+	l.instruction = instruction
+}
+
+type memoryAccessLogInterpreter[Word constraints.Integer] struct {
+	CommandInterpreter
+	log MemoryAccessLog[Word]
+}
+
+// Wraps a command interpreter so the memory accesses done by each command are recorded in the log
+// together with the command that issued them
+func MakeMemoryAccessLogInterpreter[Word constraints.Integer](impl CommandInterpreter, log MemoryAccessLog[Word]) CommandInterpreter {
+	return &memoryAccessLogInterpreter[Word]{
+		CommandInterpreter: impl,
+		log:                log,
+	}
+}
+
+func (i *memoryAccessLogInterpreter[Word]) Run(command string) (*string, error) {
+	i.log.SetInstruction(strings.TrimSpace(command))
+	defer i.log.SetInstruction("")
+
+	return i.CommandInterpreter.Run(command)
+}
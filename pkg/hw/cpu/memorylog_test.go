@@ -0,0 +1,78 @@
+package cpu
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryAccessLog_BelowCapacity(t *testing.T) {
+	log := MakeMemoryAccessLog[int32](MakeMemory[int32](16), 4)
+
+	assert.Empty(t, log.Accesses())
+	assert.Nil(t, log.Write(3, 8))
+
+	value, err := log.Read(8)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), value)
+
+	_, err = log.Read(1024)
+	assert.ErrorIs(t, err, ErrSegfault)
+
+	assert.Equal(t, []MemoryAccessRecord[int32]{
+		{Sequence: 0, Kind: MemoryAccessKind_Write, Address: 8, Value: 3},
+		{Sequence: 1, Kind: MemoryAccessKind_Read, Address: 8, Value: 3},
+		{Sequence: 2, Kind: MemoryAccessKind_Read, Address: 1024, Error: ErrSegfault},
+	}, log.Accesses())
+}
+
+func TestMemoryAccessLog_Wraparound(t *testing.T) {
+	log := MakeMemoryAccessLog[int32](MakeMemory[int32](16), 3)
+
+	for i := int32(0); i < 7; i++ {
+		assert.Nil(t, log.Write(i, 4*(i%2)))
+	}
+
+	// only the last 3 accesses are kept, oldest first
+	assert.Equal(t, []MemoryAccessRecord[int32]{
+		{Sequence: 4, Kind: MemoryAccessKind_Write, Address: 0, Value: 4},
+		{Sequence: 5, Kind: MemoryAccessKind_Write, Address: 4, Value: 5},
+		{Sequence: 6, Kind: MemoryAccessKind_Write, Address: 0, Value: 6},
+	}, log.Accesses())
+
+	accesses := log.AccessesTo(0)
+	require.Len(t, accesses, 2)
+	assert.Equal(t, uint64(4), accesses[0].Sequence)
+	assert.Equal(t, uint64(6), accesses[1].Sequence)
+	assert.Empty(t, log.AccessesTo(8))
+}
+
+func TestMemoryAccessLog_MicroCpu(t *testing.T) {
+	factories := MakeDefaultMicroCpuFactories[int32, float32](testSettings)
+	factories.MemoryBus = MemoryAccessLogFactory[int32](factories.MemoryBus, 8)
+	microCpu := MakeMicroCpu[string, int32, float32](testSettings, factories)
+
+	log, isLog := microCpu.MemoryBus().(MemoryAccessLog[int32])
+	require.True(t, isLog, "the memory access log must be reachable from the CPU")
+
+	interpreter := MakeProgramInterpreter(MakeMemoryAccessLogInterpreter[int32](makeTestCommandInterpreter(microCpu), log))
+
+	result, err := interpreter.Run(strings.Split("WW 8 w0\nWW 5 w1\n  ST w1 w0\nLD w0 w2\nRW w2", "\n"))
+	assert.Nil(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, "5", *result)
+
+	accesses := log.AccessesTo(8)
+	assert.Equal(t, []MemoryAccessRecord[int32]{
+		{Sequence: 0, Kind: MemoryAccessKind_Write, Address: 8, Value: 5, Instruction: "ST w1 w0"},
+		{Sequence: 1, Kind: MemoryAccessKind_Read, Address: 8, Value: 5, Instruction: "LD w0 w2"},
+	}, accesses)
+	assert.Equal(t, "#0 Write 0x8 value: 5 by 'ST w1 w0'", accesses[0].String())
+
+	// accesses done outside the interpreter are not attributed to the last instruction
+	_, err = log.Read(8)
+	assert.Nil(t, err)
+	assert.Empty(t, log.AccessesTo(8)[2].Instruction)
+}
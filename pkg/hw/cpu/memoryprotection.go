@@ -0,0 +1,121 @@
+package cpu
+
+import (
+	"errors"
+
+	"golang.org/x/exp/constraints"
+)
+
+var (
+	ErrWriteProtected = errors.New("write to protected memory")
+	ErrInvalidRegion  = errors.New("invalid memory region")
+)
+
+// Memory bus that can mark address ranges as read-only
+type ProtectedMemoryBus[Word constraints.Integer] interface {
+	MemoryBus[Word]
+
+	// Sets whether the addresses in the range [start, end) can be written. Later calls take precedence
+	// over earlier ones for the addresses they cover. Fails with [ErrInvalidRegion] if start > end
+	ProtectRegion(start Word, end Word, writable bool) error
+}
+
+// Address range [Start, End) of a protected memory bus
+type ProtectedRegion[Word constraints.Integer] struct {
+	Start Word
+	End   Word
+	// Whether the addresses within the region can be written
+	Writable bool
+}
+
+func (r *ProtectedRegion[Word]) validate() error {
+	if r.Start > r.End {
+		return makeError(ErrInvalidRegion, "region [0x%x, 0x%x) starts after its end", r.Start, r.End)
+	}
+
+	return nil
+}
+
+func (r *ProtectedRegion[Word]) contains(address Word) bool {
+	return r.Start <= address && address < r.End
+}
+
+type protectedMemoryBus[Word constraints.Integer] struct {
+	MemoryBus[Word]
+	regions []ProtectedRegion[Word]
+}
+
+// Wraps a memory bus so writes to protected regions fail with [ErrWriteProtected] instead of reaching the bus.
+// Memory outside the given regions is writable, regions given later take precedence over earlier ones.
+// Panics if a region is invalid
+func MakeProtectedMemoryBus[Word constraints.Integer](impl MemoryBus[Word], regions ...ProtectedRegion[Word]) ProtectedMemoryBus[Word] {
+	bus := &protectedMemoryBus[Word]{
+		MemoryBus: impl,
+	}
+
+	for _, region := range regions {
+		if err := bus.ProtectRegion(region.Start, region.End, region.Writable); err != nil {
+			panic(err)
+		}
+	}
+
+	return bus
+}
+
+// Returns a factory of memory buses protecting the given regions (See [MakeProtectedMemoryBus]). Regions
+// are given up front, so buses are protected from the start without having to reach them through the CPU
+// (See [MicroCpu.MemoryBus]), which may return them wrapped by other decorators. Panics if a region is invalid
+func ProtectedMemoryBusFactory[Word constraints.Integer](factory MemoryBusFactory[Word], regions ...ProtectedRegion[Word]) MemoryBusFactory[Word] {
+	for _, region := range regions {
+		if err := region.validate(); err != nil {
+			panic(err)
+		}
+	}
+
+	return func() MemoryBus[Word] {
+		return MakeProtectedMemoryBus[Word](factory(), regions...)
+	}
+}
+
+func (m *protectedMemoryBus[Word]) ProtectRegion(start Word, end Word, writable bool) error {
+	region := ProtectedRegion[Word]{
+		Start:    start,
+		End:      end,
+		Writable: writable,
+	}
+
+	if err := region.validate(); err != nil {
+		return err
+	}
+
+	m.regions = append(m.regions, region)
+	return nil
+}
+
+// Returns the latest region covering the given byte address, or nil if no region covers it
+func (m *protectedMemoryBus[Word]) regionOf(address Word) *ProtectedRegion[Word] {
+	for i := len(m.regions) - 1; i >= 0; i-- {
+		if m.regions[i].contains(address) {
+			return &m.regions[i]
+		}
+	}
+
+	return nil
+}
+
+func (m *protectedMemoryBus[Word]) Write(value Word, address Word) error {
+	// every byte of the word must be writable, bytes past the end of the address space do not exist
+	for offset := 0; offset < Sizeof[Word](); offset++ {
+		byteAddress := address + Word(offset)
+
+		if byteAddress < address {
+			break
+		}
+
+		if region := m.regionOf(byteAddress); region != nil && !region.Writable {
+			return makeError(ErrWriteProtected, "tried writing %v into address 0x%x, byte 0x%x is within read-only region [0x%x, 0x%x)", value, address, byteAddress, region.Start, region.End)
+		}
+	}
+
+	return m.MemoryBus.Write(value, address)
+}
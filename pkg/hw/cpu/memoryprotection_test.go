@@ -0,0 +1,93 @@
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtectedMemoryBus_ProtectedWrite(t *testing.T) {
+	memory := MakeMemory[int32](16)
+	assert.Nil(t, memory.Write(3, 8))
+
+	bus := MakeProtectedMemoryBus[int32](memory, ProtectedRegion[int32]{Start: 8, End: 16})
+
+	assert.ErrorIs(t, bus.Write(1, 8), ErrWriteProtected)
+	assert.ErrorIs(t, bus.Write(1, 12), ErrWriteProtected)
+	assert.Nil(t, bus.Write(1, 4))
+	assert.Nil(t, bus.Write(1, 16))
+
+	value, err := memory.Read(8)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), value, "protected writes must not reach the bus")
+}
+
+func TestProtectedMemoryBus_PartialOverlap(t *testing.T) {
+	bus := MakeProtectedMemoryBus[int32](MakeMemory[int32](16), ProtectedRegion[int32]{Start: 6, End: 7})
+
+	assert.ErrorIs(t, bus.Write(1, 4), ErrWriteProtected, "words partially within the region are protected")
+	assert.Nil(t, bus.Write(1, 8))
+}
+
+func TestProtectedMemoryBus_WritableOverride(t *testing.T) {
+	bus := MakeProtectedMemoryBus[int32](MakeMemory[int32](16),
+		ProtectedRegion[int32]{Start: 0, End: 32},
+		ProtectedRegion[int32]{Start: 8, End: 12, Writable: true})
+
+	assert.ErrorIs(t, bus.Write(1, 4), ErrWriteProtected)
+	assert.Nil(t, bus.Write(1, 8))
+
+	assert.Nil(t, bus.ProtectRegion(8, 12, false))
+	assert.ErrorIs(t, bus.Write(1, 8), ErrWriteProtected, "later regions take precedence")
+
+	partial := MakeProtectedMemoryBus[int32](MakeMemory[int32](16),
+		ProtectedRegion[int32]{Start: 0, End: 32},
+		ProtectedRegion[int32]{Start: 8, End: 9, Writable: true})
+
+	assert.ErrorIs(t, partial.Write(1, 8), ErrWriteProtected, "bytes 9-11 of the word are still read-only")
+}
+
+func TestProtectedMemoryBus_LastWordOfAddressSpace(t *testing.T) {
+	signed := MakeProtectedMemoryBus[int32](MakeMemory[int32](16), ProtectedRegion[int32]{Start: 0x7ffffff0, End: 0x7fffffff})
+	assert.ErrorIs(t, signed.Write(1, 0x7ffffffc), ErrWriteProtected)
+
+	unsigned := MakeProtectedMemoryBus[uint32](MakeMemory[uint32](16), ProtectedRegion[uint32]{Start: 0xfffffff0, End: 0xffffffff})
+	assert.ErrorIs(t, unsigned.Write(1, 0xfffffffc), ErrWriteProtected)
+	assert.ErrorIs(t, unsigned.Write(1, 0xfffffff0), ErrWriteProtected)
+}
+
+func TestProtectedMemoryBus_Read(t *testing.T) {
+	memory := MakeMemory[int32](16)
+	assert.Nil(t, memory.Write(42, 8))
+
+	bus := MakeProtectedMemoryBus[int32](memory, ProtectedRegion[int32]{Start: 0, End: 64})
+
+	value, err := bus.Read(8)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(42), value)
+}
+
+func TestProtectedMemoryBus_InvalidRegion(t *testing.T) {
+	bus := MakeProtectedMemoryBus[int32](MakeMemory[int32](16))
+
+	assert.ErrorIs(t, bus.ProtectRegion(8, 4, false), ErrInvalidRegion)
+	assert.Nil(t, bus.Write(1, 4))
+
+	assert.Panics(t, func() {
+		ProtectedMemoryBusFactory[int32](func() MemoryBus[int32] { return MakeMemory[int32](16) }, ProtectedRegion[int32]{Start: 8, End: 4})
+	})
+}
+
+func TestProtectedMemoryBusFactory(t *testing.T) {
+	factories := MakeDefaultMicroCpuFactories[int32, float32](testSettings)
+	factories.MemoryBus = ProtectedMemoryBusFactory[int32](factories.MemoryBus, ProtectedRegion[int32]{Start: 0, End: 8})
+	microCpu := MakeMicroCpu[string, int32, float32](testSettings, factories)
+
+	registers := microCpu.AllWordRegisters()
+	assert.Nil(t, registers.Write(4, "w0"))
+	assert.Nil(t, registers.Write(1, "w1"))
+	assert.ErrorIs(t, microCpu.Memory().Store("w1", "w0"), ErrWriteProtected)
+
+	assert.Nil(t, registers.Write(8, "w0"))
+	assert.Nil(t, microCpu.Memory().Store("w1", "w0"))
+}
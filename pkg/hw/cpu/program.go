@@ -0,0 +1,82 @@
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"golang.org/x/exp/constraints"
+)
+
+// Returns the factories of a MicroCpu with string register names and untraced hardware. Registers are named
+// pc, sp, fp and lr (state registers), _w0.._wN (internal word registers), w0..wN (public word registers)
+// and f0..fN (float registers)
+func MakeDefaultMicroCpuFactories[Word constraints.Integer, Float constraints.Float](settings MicroCpuSettings) MicroCpuFactories[string, Word, Float] {
+	return MicroCpuFactories[string, Word, Float]{
+		StateRegisterNames: StateRegisters[string]{
+			ProgramCounter: "pc",
+			StackPointer:   "sp",
+			FramePointer:   "fp",
+			LinkRegister:   "lr",
+		},
+		InternalWordRegisterName: func(index int) string {
+			return fmt.Sprintf("_w%v", index)
+		},
+		PublicWordRegisterName: func(index int) string {
+			return fmt.Sprintf("w%v", index)
+		},
+		FloatRegisterName: func(index int) string {
+			return fmt.Sprintf("f%v", index)
+		},
+		StateRegisters:        MakeRegisters[string, Word],
+		InternalWordRegisters: MakeRegisters[string, Word],
+		PublicWordRegisters:   MakeRegisters[string, Word],
+		FloatRegisters:        MakeRegisters[string, Float],
+		WordMov:               MakeRegisterInterchange[string, Word],
+		FloatMov:              MakeRegisterInterchange[string, Float],
+		WordToFloat:           MakeRegisterConversion[string, Word, Float],
+		FloatToWord:           MakeRegisterConversion[string, Float, Word],
+		WordAlu:               MakeIntegerAlu[string, Word],
+		FloatAlu:              MakeFloatAlu[string, Float],
+		MemoryBus: func() MemoryBus[Word] {
+			return MakeMemory[Word](settings.TotalMemory)
+		},
+		MemoryAccess: MakeMemoryAccess[string, Word],
+	}
+}
+
+// Parses register names of MicroCpus built with [MakeDefaultMicroCpuFactories]. Unknown names are
+// reported by the register banks when accessed
+func ParseDefaultRegister(name string) (string, error) {
+	return name, nil
+}
+
+// Builds a MicroCpu with the default factories (See [MakeDefaultMicroCpuFactories]), runs a program on it and
+// returns the last word read by the program, or nil if the program did not read any word. Programs running more
+// than maxSteps lines fail with [ErrStepLimitExceeded] (See [MakeProgramInterpreterWithStepLimit]), zero means no
+// limit. If not nil, decorate is called with the default factories before building the CPU so callers can replace
+// or wrap them (e.g. to trace hardware operations). The program is stopped with [ErrInterrupted] once ctx is done
+func RunProgram[Word constraints.Integer, Float constraints.Float](ctx context.Context, src string, settings MicroCpuSettings, maxSteps int, decorate func(*MicroCpuFactories[string, Word, Float])) (*Word, error) {
+	factories := MakeDefaultMicroCpuFactories[Word, Float](settings)
+
+	if decorate != nil {
+		decorate(&factories)
+	}
+
+	microCpu := MakeMicroCpu[string, Word, Float](settings, factories)
+	interpreter := MakeProgramInterpreterWithStepLimit(MakeSanitizedCommandInterpreter(MakeCommandInterpreter(MakeMicroCpuInterpreter(microCpu, ParseDefaultRegister))), maxSteps)
+
+	result, err := interpreter.RunContext(ctx, strings.Split(src, "\n"))
+
+	if err != nil || result == nil {
+		return nil, err
+	}
+
+	word, err := parseWord[Word](*result)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &word, nil
+}
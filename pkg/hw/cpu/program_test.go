@@ -0,0 +1,92 @@
+package cpu
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testSettings = MicroCpuSettings{
+	TotalInternalWordRegisters: 4,
+	TotalPublicWordRegisters:   8,
+	TotalFloatRegisters:        8,
+	TotalMemory:                1024,
+}
+
+func TestRunProgram(t *testing.T) {
+	result, err := RunProgram[int32, float32](context.Background(), `
+		WW 42 w0 // write 42 into w0
+		RW w0
+	`, testSettings, 0, nil)
+
+	assert.Nil(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, int32(42), *result)
+}
+
+func TestRunProgram_NoResult(t *testing.T) {
+	result, err := RunProgram[int32, float32](context.Background(), "WW 42 w0", testSettings, 0, nil)
+
+	assert.Nil(t, err)
+	assert.Nil(t, result)
+}
+
+func TestRunProgram_Decorated(t *testing.T) {
+	writes := 0
+
+	result, err := RunProgram[int32, float32](context.Background(), "WW 1 w1\nRW w1", testSettings, 0, func(factories *MicroCpuFactories[string, int32, float32]) {
+		publicWordRegisters := factories.PublicWordRegisters
+
+		factories.PublicWordRegisters = func(registers ...string) RegisterBank[string, int32] {
+			return &countedWritesRegisterBank{RegisterBank: publicWordRegisters(registers...), writes: &writes}
+		}
+	})
+
+	assert.Nil(t, err)
+	require.NotNil(t, result)
+	assert.Equal(t, int32(1), *result)
+	assert.Equal(t, 1, writes)
+}
+
+func TestRunProgram_UnknownRegister(t *testing.T) {
+	_, err := RunProgram[int32, float32](context.Background(), "RW w42", testSettings, 0, nil)
+
+	assert.ErrorIs(t, err, ErrUnknownRegister)
+}
+
+func TestRunProgram_Interrupted(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+
+	go func() {
+		_, err := RunProgram[int32, float32](ctx, "WW 1 w0\nloop:\n  RW w0\n  JNZ loop", testSettings, 0, nil)
+		done <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		var programError *ProgramError
+		require.True(t, errors.As(err, &programError), "expected a program error, got %v", err)
+		assert.ErrorIs(t, err, ErrInterrupted)
+		assert.Contains(t, []int{3, 4}, programError.Line)
+	case <-time.After(5 * time.Second):
+		t.Fatal("program looping forever was not stopped when interrupted")
+	}
+}
+
+type countedWritesRegisterBank struct {
+	RegisterBank[string, int32]
+	writes *int
+}
+
+func (b *countedWritesRegisterBank) Write(value int32, r string) error {
+	*b.writes++
+	return b.RegisterBank.Write(value, r)
+}
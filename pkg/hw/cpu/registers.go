@@ -2,6 +2,7 @@ package cpu
 
 import (
 	"errors"
+	"fmt"
 )
 
 var (
@@ -15,11 +16,20 @@ type RegisterName interface {
 
 type RegisterIndex[Register RegisterName, Type Number] interface {
 	Get(r Register) (*Type, error)
+	Registers() []Register
 }
 
+// Values of a set of registers at a given point in time
+type RegisterSnapshot[Register RegisterName, Type Number] map[Register]Type
+
 type RegisterBank[Register RegisterName, Type Number] interface {
 	Read(r Register) (Type, error)
 	Write(value Type, r Register) error
+	// Returns the current values of all the registers of the bank
+	Snapshot() RegisterSnapshot[Register, Type]
+	// Writes all the register values of a snapshot into the bank. Registers not in the snapshot keep their
+	// values. Fails without modifying any register if the snapshot contains registers unknown to the bank
+	Restore(snapshot RegisterSnapshot[Register, Type]) error
 }
 
 type RegisterBankFactory[Register RegisterName, Type Number] func(registers ...Register) RegisterBank[Register, Type]
@@ -56,6 +66,39 @@ func (b *registerBankFromIndex[Register, Type]) Write(value Type, r Register) er
 	return nil
 }
 
+func (b *registerBankFromIndex[Register, Type]) Snapshot() RegisterSnapshot[Register, Type] {
+	registers := b.index.Registers()
+	snapshot := make(RegisterSnapshot[Register, Type], len(registers))
+
+	for _, r := range registers {
+		if reg, err := b.index.Get(r); err == nil {
+			snapshot[r] = *reg
+		}
+	}
+
+	return snapshot
+}
+
+func (b *registerBankFromIndex[Register, Type]) Restore(snapshot RegisterSnapshot[Register, Type]) error {
+	regs := make(map[Register]*Type, len(snapshot))
+
+	for r := range snapshot {
+		reg, err := b.index.Get(r)
+
+		if err != nil {
+			return err
+		}
+
+		regs[r] = reg
+	}
+
+	for r, value := range snapshot {
+		*regs[r] = value
+	}
+
+	return nil
+}
+
 type registers[Register RegisterName, Type Number] struct {
 	rs map[Register]*Type
 }
@@ -80,6 +123,16 @@ func (rs *registers[Register, Type]) Get(r Register) (*Type, error) {
 	}
 }
 
+func (rs *registers[Register, Type]) Registers() []Register {
+	registers := make([]Register, 0, len(rs.rs))
+
+	for r := range rs.rs {
+		registers = append(registers, r)
+	}
+
+	return registers
+}
+
 type joinedRegisterBanks[Register RegisterName, Type Number] struct {
 	banks []RegisterBank[Register, Type]
 }
@@ -116,6 +169,53 @@ func (rs *joinedRegisterBanks[Register, Type]) Write(value Type, r Register) err
 	return makeError(ErrUnknownRegister, "'%v'", r)
 }
 
+func (rs *joinedRegisterBanks[Register, Type]) Snapshot() RegisterSnapshot[Register, Type] {
+	snapshot := make(RegisterSnapshot[Register, Type])
+
+	// registers are read from the first bank containing them, same as Read()
+	for i := len(rs.banks) - 1; i >= 0; i-- {
+		for r, value := range rs.banks[i].Snapshot() {
+			snapshot[r] = value
+		}
+	}
+
+	return snapshot
+}
+
+func (rs *joinedRegisterBanks[Register, Type]) Restore(snapshot RegisterSnapshot[Register, Type]) error {
+	bankSnapshots := make([]RegisterSnapshot[Register, Type], len(rs.banks))
+	bankRegisters := make([]RegisterSnapshot[Register, Type], len(rs.banks))
+
+	for i, bank := range rs.banks {
+		bankSnapshots[i] = make(RegisterSnapshot[Register, Type])
+		bankRegisters[i] = bank.Snapshot()
+	}
+
+	for r, value := range snapshot {
+		found := false
+
+		for i := range rs.banks {
+			if _, contains := bankRegisters[i][r]; contains {
+				bankSnapshots[i][r] = value
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return makeError(ErrUnknownRegister, "'%v'", r)
+		}
+	}
+
+	for i, bank := range rs.banks {
+		if err := bank.Restore(bankSnapshots[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func JoinRegisterBanks[Register RegisterName, Type Number](banks ...RegisterBank[Register, Type]) RegisterBank[Register, Type] {
 	return &joinedRegisterBanks[Register, Type]{
 		banks: banks,
@@ -150,6 +250,9 @@ func (ri *registerInterchange[Register, Type]) Move(src Register, dst Register)
 
 type RegisterConversion[Register RegisterName] interface {
 	Convert(src Register, dst Register) error
+	// Returns the source value and the converted value of the last successful conversion, formatted as
+	// strings since the value types depend on the conversion. Both are empty if nothing was converted yet
+	LastConversion() (source string, result string)
 }
 
 type RegisterConversionFactory[Register RegisterName, SrcType Number, DstType Number] func(src RegisterBank[Register, SrcType], dst RegisterBank[Register, DstType]) RegisterConversion[Register]
@@ -157,6 +260,9 @@ type RegisterConversionFactory[Register RegisterName, SrcType Number, DstType Nu
 type registerConversion[Register RegisterName, SrcType Number, DstType Number] struct {
 	src RegisterBank[Register, SrcType]
 	dst RegisterBank[Register, DstType]
+
+	lastSource    *SrcType
+	lastConverted *DstType
 }
 
 func MakeRegisterConversion[Register RegisterName, SrcType Number, DstType Number](src RegisterBank[Register, SrcType], dst RegisterBank[Register, DstType]) RegisterConversion[Register] {
@@ -173,5 +279,21 @@ func (rc *registerConversion[Register, SrcType, DstType]) Convert(src Register,
 		return err
 	}
 
-	return rc.dst.Write(DstType(srcValue), dst)
+	converted := DstType(srcValue)
+
+	if err := rc.dst.Write(converted, dst); err != nil {
+		return err
+	}
+
+	rc.lastSource = &srcValue
+	rc.lastConverted = &converted
+	return nil
+}
+
+func (rc *registerConversion[Register, SrcType, DstType]) LastConversion() (string, string) {
+	if rc.lastSource == nil {
+		return "", ""
+	}
+
+	return fmt.Sprint(*rc.lastSource), fmt.Sprint(*rc.lastConverted)
 }
@@ -0,0 +1,96 @@
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegisterBank_SnapshotRestore(t *testing.T) {
+	bank := MakeRegisters[string, int32]("r0", "r1", "r2")
+
+	assert.Nil(t, bank.Write(1, "r0"))
+	assert.Nil(t, bank.Write(2, "r1"))
+	assert.Nil(t, bank.Write(3, "r2"))
+
+	snapshot := bank.Snapshot()
+	assert.Equal(t, RegisterSnapshot[string, int32]{"r0": 1, "r1": 2, "r2": 3}, snapshot)
+
+	assert.Nil(t, bank.Write(42, "r0"))
+	assert.Nil(t, bank.Write(42, "r2"))
+
+	assert.Nil(t, bank.Restore(snapshot))
+	assert.Equal(t, snapshot, bank.Snapshot())
+}
+
+func TestRegisterBank_PartialRestore(t *testing.T) {
+	bank := MakeRegisters[string, float32]("f0", "f1")
+
+	assert.Nil(t, bank.Write(1.5, "f0"))
+	assert.Nil(t, bank.Write(2.5, "f1"))
+	assert.Nil(t, bank.Restore(RegisterSnapshot[string, float32]{"f1": 0.5}))
+
+	assert.Equal(t, RegisterSnapshot[string, float32]{"f0": 1.5, "f1": 0.5}, bank.Snapshot())
+}
+
+func TestRegisterBank_RestoreUnknownRegister(t *testing.T) {
+	bank := MakeRegisters[string, int32]("r0")
+
+	assert.Nil(t, bank.Write(1, "r0"))
+	assert.ErrorIs(t, bank.Restore(RegisterSnapshot[string, int32]{"r0": 2, "r1": 3}), ErrUnknownRegister)
+
+	value, err := bank.Read("r0")
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), value, "failed restores must not modify any register")
+}
+
+func TestJoinedRegisterBanks_SnapshotRestore(t *testing.T) {
+	state := MakeRegisters[string, int32]("pc", "sp")
+	public := MakeRegisters[string, int32]("w0", "w1")
+	bank := JoinRegisterBanks(state, public)
+
+	assert.Nil(t, bank.Write(4, "pc"))
+	assert.Nil(t, bank.Write(8, "w1"))
+
+	snapshot := bank.Snapshot()
+	assert.Equal(t, RegisterSnapshot[string, int32]{"pc": 4, "sp": 0, "w0": 0, "w1": 8}, snapshot)
+
+	assert.Nil(t, bank.Write(0, "pc"))
+	assert.Nil(t, bank.Write(16, "w0"))
+	assert.Nil(t, bank.Restore(snapshot))
+
+	assert.Equal(t, snapshot, bank.Snapshot())
+	assert.Equal(t, RegisterSnapshot[string, int32]{"pc": 4, "sp": 0}, state.Snapshot())
+	assert.ErrorIs(t, bank.Restore(RegisterSnapshot[string, int32]{"f0": 1}), ErrUnknownRegister)
+}
+
+func TestRegisterConversion_LastConversion(t *testing.T) {
+	words := MakeRegisters[string, int32]("w0")
+	floats := MakeRegisters[string, float32]("f0")
+	wordToFloat := MakeRegisterConversion[string, int32, float32](words, floats)
+	floatToWord := MakeRegisterConversion[string, float32, int32](floats, words)
+
+	source, result := wordToFloat.LastConversion()
+	assert.Empty(t, source)
+	assert.Empty(t, result)
+
+	assert.Nil(t, words.Write(3, "w0"))
+	assert.Nil(t, wordToFloat.Convert("w0", "f0"))
+
+	source, result = wordToFloat.LastConversion()
+	assert.Equal(t, "3", source)
+	assert.Equal(t, "3", result)
+
+	assert.Nil(t, floats.Write(2.75, "f0"))
+	assert.Nil(t, floatToWord.Convert("f0", "w0"))
+
+	source, result = floatToWord.LastConversion()
+	assert.Equal(t, "2.75", source)
+	assert.Equal(t, "2", result)
+
+	assert.ErrorIs(t, floatToWord.Convert("f1", "w0"), ErrUnknownRegister)
+
+	source, result = floatToWord.LastConversion()
+	assert.Equal(t, "2.75", source, "failed conversions must not replace the last conversion")
+	assert.Equal(t, "2", result)
+}
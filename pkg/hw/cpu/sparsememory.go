@@ -0,0 +1,78 @@
+package cpu
+
+import (
+	"golang.org/x/exp/constraints"
+)
+
+type sparseMemory[Word constraints.Integer] struct {
+	words     int
+	pageWords int
+	pages     map[int][]Word
+}
+
+// Returns a memory bus of the given size in words that only allocates the pages of pageWords words that are written.
+// Reading a page that was never written returns zeros. Use it instead of [MakeMemory] for large address spaces
+// where programs only touch a few regions
+func MakeSparseMemory[Word constraints.Integer](words int, pageWords int) MemoryBus[Word] {
+	if pageWords <= 0 {
+		panic("sparse memory page size must be greater than zero")
+	}
+
+	return &sparseMemory[Word]{
+		words:     words,
+		pageWords: pageWords,
+		pages:     make(map[int][]Word),
+	}
+}
+
+func SparseMemoryFactory[Word constraints.Integer](words int, pageWords int) MemoryBusFactory[Word] {
+	return func() MemoryBus[Word] {
+		return MakeSparseMemory[Word](words, pageWords)
+	}
+}
+
+// Returns the page and the offset within the page of the word at the given address
+func (m *sparseMemory[Word]) locate(address Word) (int, int, error) {
+	if uint64(address)%uint64(Sizeof[Word]()) != 0 {
+		return 0, 0, makeError(ErrUnalignedAccess, "tried accessing address 0x%x which is not aligned to the %v bytes word boundary", address, Sizeof[Word]())
+	}
+	// checked in uint64 before converting to int, which would turn addresses above 2^63 negative
+	if address < 0 || uint64(address)/uint64(Sizeof[Word]()) >= uint64(m.words) {
+		return 0, 0, ErrSegfault
+	}
+
+	word := int(uint64(address) / uint64(Sizeof[Word]()))
+	return word / m.pageWords, word % m.pageWords, nil
+}
+
+func (m *sparseMemory[Word]) Read(address Word) (Word, error) {
+	page, offset, err := m.locate(address)
+
+	if err != nil {
+		return Zero[Word](), err
+	}
+
+	if words, mapped := m.pages[page]; mapped {
+		return words[offset], nil
+	}
+
+	return Zero[Word](), nil
+}
+
+func (m *sparseMemory[Word]) Write(value Word, address Word) error {
+	page, offset, err := m.locate(address)
+
+	if err != nil {
+		return err
+	}
+
+	words, mapped := m.pages[page]
+
+	if !mapped {
+		words = make([]Word, m.pageWords)
+		m.pages[page] = words
+	}
+
+	words[offset] = value
+	return nil
+}
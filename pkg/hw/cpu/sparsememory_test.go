@@ -0,0 +1,50 @@
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSparseMemory(t *testing.T) {
+	// 1G words, only the written pages are allocated
+	memory := MakeSparseMemory[int32](1<<30, 256)
+
+	value, err := memory.Read(0x1000)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(0), value)
+
+	assert.Nil(t, memory.Write(42, 0x10000000))
+	assert.Nil(t, memory.Write(7, 0x10000004))
+
+	value, err = memory.Read(0x10000000)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(42), value)
+
+	value, err = memory.Read(0x10000004)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(7), value)
+
+	assert.Len(t, memory.(*sparseMemory[int32]).pages, 1)
+}
+
+func TestSparseMemory_InvalidAccesses(t *testing.T) {
+	memory := MakeSparseMemory[int32](16, 4)
+
+	assert.ErrorIs(t, memory.Write(1, 2), ErrUnalignedAccess)
+	assert.ErrorIs(t, memory.Write(1, 64), ErrSegfault)
+	assert.ErrorIs(t, memory.Write(1, -4), ErrSegfault)
+	assert.Nil(t, memory.Write(1, 60))
+
+	_, err := memory.Read(64)
+	assert.ErrorIs(t, err, ErrSegfault)
+
+	unsigned := MakeSparseMemory[uint64](16, 4)
+
+	assert.ErrorIs(t, unsigned.Write(1, 0x8000000000000008), ErrSegfault)
+	assert.ErrorIs(t, unsigned.Write(1, 0xfffffffffffffff8), ErrSegfault)
+	assert.Nil(t, unsigned.Write(1, 120))
+
+	_, err = unsigned.Read(0x8000000000000008)
+	assert.ErrorIs(t, err, ErrSegfault)
+}
@@ -0,0 +1,53 @@
+package cpu
+
+import (
+	"errors"
+
+	"golang.org/x/exp/constraints"
+)
+
+var ErrUninitializedRead = errors.New("read of uninitialized memory")
+
+type uninitializedReadCheck[Word constraints.Integer] struct {
+	MemoryBus[Word]
+	written map[Word]struct{}
+}
+
+// Wraps a memory bus so reads of words that were never written through the wrapper fail with
+// [ErrUninitializedRead]. Tracking which words were written has a cost, so only wrap buses when
+// looking for uses of uninitialized memory
+func MakeUninitializedReadCheck[Word constraints.Integer](impl MemoryBus[Word]) MemoryBus[Word] {
+	return &uninitializedReadCheck[Word]{
+		MemoryBus: impl,
+		written:   make(map[Word]struct{}),
+	}
+}
+
+func UninitializedReadCheckFactory[Word constraints.Integer](factory MemoryBusFactory[Word]) MemoryBusFactory[Word] {
+	return func() MemoryBus[Word] {
+		return MakeUninitializedReadCheck[Word](factory())
+	}
+}
+
+func (m *uninitializedReadCheck[Word]) Read(address Word) (Word, error) {
+	value, err := m.MemoryBus.Read(address)
+
+	if err != nil {
+		return value, err
+	}
+
+	if _, written := m.written[address]; !written {
+		return value, makeError(ErrUninitializedRead, "tried reading address 0x%x, which was never written", address)
+	}
+
+	return value, nil
+}
+
+func (m *uninitializedReadCheck[Word]) Write(value Word, address Word) error {
+	if err := m.MemoryBus.Write(value, address); err != nil {
+		return err
+	}
+
+	m.written[address] = struct{}{}
+	return nil
+}
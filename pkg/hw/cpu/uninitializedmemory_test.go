@@ -0,0 +1,25 @@
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUninitializedReadCheck(t *testing.T) {
+	memory := MakeUninitializedReadCheck[int32](MakeSparseMemory[int32](16, 4))
+
+	_, err := memory.Read(8)
+	assert.ErrorIs(t, err, ErrUninitializedRead)
+
+	assert.Nil(t, memory.Write(3, 8))
+
+	value, err := memory.Read(8)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), value)
+
+	assert.ErrorIs(t, memory.Write(1, 64), ErrSegfault)
+
+	_, err = memory.Read(64)
+	assert.ErrorIs(t, err, ErrSegfault, "bus errors take precedence over uninitialized reads")
+}
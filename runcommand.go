@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/Manu343726/cucaracha/pkg/hw/cpu"
+)
+
+// Exit codes of the run command for programs that do not finish. Programs that finish exit with their
+// result, so these codes cannot be told apart from programs returning the same value
+const (
+	runExitError       = 125
+	runExitMaxSteps    = 124
+	runExitInterrupted = 130
+)
+
+// Returns the exit code of a finished program: its result (the last word read with RW) truncated
+// to 8 bits, or 0 if the program did not read any word
+func programExitCode(result *Word) int {
+	if result == nil {
+		return 0
+	}
+
+	return int(uint8(*result))
+}
+
+// Runs a program file without printing anything unless verbose, for scripts that check the program
+// result through the exit code. Returns the process exit code
+func runCommand(args []string) int {
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %v run [-verbose] [-max-steps N] <program file>\n\nRuns a MicroCpu program file and exits with its result (the last word read with RW)\ntruncated to 8 bits. Programs that do not finish exit with %v if they run more than\n-max-steps lines, %v if interrupted and %v on errors.\n", os.Args[0], runExitMaxSteps, runExitInterrupted, runExitError)
+		flags.PrintDefaults()
+	}
+	verbose := flags.Bool("verbose", false, "trace hardware operations and print the program result")
+	maxSteps := flags.Int("max-steps", defaultMaxSteps, "stop programs that run more than this number of lines (0 means unlimited)")
+
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	var tracer Tracer = &NullTracer{}
+
+	if *verbose {
+		tracer = MakeTextTracer(os.Stdout)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	result, err := runProgramFile(ctx, flags.Arg(0), *maxSteps, MakeTracerWithContextStack(tracer))
+
+	if err != nil {
+		// errors are always reported, on stderr so scripts can still capture stdout
+		printProgramError(os.Stderr, err)
+
+		switch {
+		case errors.Is(err, cpu.ErrStepLimitExceeded):
+			return runExitMaxSteps
+		case errors.Is(err, cpu.ErrInterrupted):
+			return runExitInterrupted
+		default:
+			return runExitError
+		}
+	}
+
+	if *verbose {
+		if result != nil {
+			fmt.Printf("program finished. result: %v\n", *result)
+		} else {
+			fmt.Println("program finished")
+		}
+	}
+
+	return programExitCode(result)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeProgram(t *testing.T, source string) string {
+	path := filepath.Join(t.TempDir(), "program.txt")
+	require.Nil(t, os.WriteFile(path, []byte(source), 0o644))
+	return path
+}
+
+// Runs a command with stdout redirected into a file, returns the exit code and the output
+func captureStdout(t *testing.T, command func() int) (int, string) {
+	output, err := os.Create(filepath.Join(t.TempDir(), "stdout.txt"))
+	require.Nil(t, err)
+	defer output.Close()
+
+	stdout := os.Stdout
+	os.Stdout = output
+	defer func() { os.Stdout = stdout }()
+
+	code := command()
+	return code, readFile(t, output.Name())
+}
+
+func runCommandOutput(t *testing.T, args ...string) (int, string) {
+	return captureStdout(t, func() int { return runCommand(args) })
+}
+
+func TestRunCommand_ExitCode(t *testing.T) {
+	cases := []struct {
+		name     string
+		source   string
+		expected int
+	}{
+		{name: "result", source: "WW 42 w0\nRW w0", expected: 42},
+		{name: "result truncated to 8 bits", source: "WW 300 w0\nRW w0", expected: 44},
+		{name: "negative result", source: "WW -1 w0\nRW w0", expected: 255},
+		{name: "no result", source: "WW 1 w0", expected: 0},
+		{name: "program error", source: "RW w42", expected: runExitError},
+		{name: "step limit", source: "loop:\nJMP loop", expected: runExitMaxSteps},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			code, output := runCommandOutput(t, "-max-steps", "100", writeProgram(t, c.source))
+
+			assert.Equal(t, c.expected, code)
+			assert.Empty(t, output, "nothing is printed unless verbose")
+		})
+	}
+}
+
+func TestRunCommand_Verbose(t *testing.T) {
+	code, output := runCommandOutput(t, "-verbose", writeProgram(t, "WW 7 w0\nRW w0"))
+
+	assert.Equal(t, 7, code)
+	assert.Contains(t, output, "Read r: w0 result: 7")
+	assert.Contains(t, output, "program finished. result: 7\n")
+}
+
+func TestRunCommand_Usage(t *testing.T) {
+	code, _ := runCommandOutput(t)
+	assert.Equal(t, 2, code)
+
+	code, _ = runCommandOutput(t, "-unknown-flag", "program.txt")
+	assert.Equal(t, 2, code)
+
+	code, _ = runCommandOutput(t, filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Equal(t, runExitError, code)
+}
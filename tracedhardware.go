@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/Manu343726/cucaracha/pkg/hw/cpu"
 	"golang.org/x/exp/constraints"
@@ -14,6 +15,7 @@ type Trace struct {
 	Operands     map[string]string
 	Result       string
 	Error        error
+	Timestamp    time.Time
 }
 
 func (t *Trace) Depth() int {
@@ -104,6 +106,11 @@ func MakeTracerWithContextStack(tracer Tracer) TracerWithContextStack {
 
 func (t *tracerWithContextStack) SaveTrace(trace *Trace) {
 	trace.ContextStack = append([]string{}, t.stack...)
+
+	if trace.Timestamp.IsZero() {
+		trace.Timestamp = time.Now()
+	}
+
 	t.Tracer.SaveTrace(trace)
 }
 
@@ -234,6 +241,39 @@ func (t *tracedRegisterBank[Register, Type]) Write(value Type, dest Register) er
 	return err
 }
 
+func (t *tracedRegisterBank[Register, Type]) Snapshot() cpu.RegisterSnapshot[Register, Type] {
+	t.PushContext("Snapshot()")
+
+	snapshot := t.RegisterBank.Snapshot()
+
+	t.SaveTrace(&Trace{
+		Operation: "Snapshot",
+		Result:    fmt.Sprint(snapshot),
+	})
+
+	t.PopContext()
+
+	return snapshot
+}
+
+func (t *tracedRegisterBank[Register, Type]) Restore(snapshot cpu.RegisterSnapshot[Register, Type]) error {
+	t.PushContext("Restore(snapshot: %v)", snapshot)
+
+	err := t.RegisterBank.Restore(snapshot)
+
+	t.SaveTrace(&Trace{
+		Operation: "Restore",
+		Operands: map[string]string{
+			"snapshot": fmt.Sprint(snapshot),
+		},
+		Error: err,
+	})
+
+	t.PopContext()
+
+	return err
+}
+
 type tracedMemoryBus[Word constraints.Integer] struct {
 	tracedModule
 	cpu.MemoryBus[Word]
@@ -393,14 +433,22 @@ func (t *tracedRegisterConversion[Register, Word]) Convert(src Register, dest Re
 
 	err := t.RegisterConversion.Convert(src, dest)
 
-	t.SaveTrace(&Trace{
+	trace := &Trace{
 		Operation: "Convert",
 		Operands: map[string]string{
 			"src":  fmt.Sprint(src),
 			"dest": fmt.Sprint(dest),
 		},
 		Error: err,
-	})
+	}
+
+	if err == nil {
+		srcValue, result := t.RegisterConversion.LastConversion()
+		trace.Operands["value"] = srcValue
+		trace.Result = result
+	}
+
+	t.SaveTrace(trace)
 
 	t.PopContext()
 
@@ -0,0 +1,315 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSON representation of a trace, see [JSONTracer]
+type jsonTrace struct {
+	Depth     int               `json:"depth"`
+	Context   string            `json:"context"`
+	Message   string            `json:"message"`
+	Timestamp string            `json:"timestamp"`
+	Operation string            `json:"operation"`
+	Operands  map[string]string `json:"operands,omitempty"`
+	Result    string            `json:"result,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+func makeJsonTrace(trace *Trace) jsonTrace {
+	result := jsonTrace{
+		Depth:     trace.Depth(),
+		Context:   trace.Context(),
+		Message:   trace.String(),
+		Timestamp: trace.Timestamp.Format(time.RFC3339Nano),
+		Operation: trace.Operation,
+		Operands:  trace.Operands,
+		Result:    trace.Result,
+	}
+
+	if trace.Error != nil {
+		result.Error = trace.Error.Error()
+	}
+
+	return result
+}
+
+// Writes traces as JSON objects, one per line
+type JSONTracer struct {
+	encoder *json.Encoder
+}
+
+func MakeJSONTracer(output io.Writer) *JSONTracer {
+	return &JSONTracer{
+		encoder: json.NewEncoder(output),
+	}
+}
+
+func (t *JSONTracer) SaveTrace(trace *Trace) {
+	if err := t.encoder.Encode(makeJsonTrace(trace)); err != nil {
+		fmt.Fprintf(os.Stderr, "could not write trace: %v\n", err)
+	}
+}
+
+// Suppresses traces not belonging to a set of traced components. Components are identified
+// by the name given to the traced module (e.g. "main memory bus"), and a trace belongs to a
+// component if any frame of its context stack was pushed by that component.
+type FilteredTracer struct {
+	Tracer
+	allow []string
+	deny  []string
+}
+
+// Creates a tracer that only saves traces belonging to any of the allowed components (all if
+// the allow list is empty) and none of the denied components
+func MakeFilteredTracer(tracer Tracer, allow []string, deny []string) *FilteredTracer {
+	return &FilteredTracer{
+		Tracer: tracer,
+		allow:  allow,
+		deny:   deny,
+	}
+}
+
+// Returns the contexts a trace was saved from, including the context being entered or
+// exited for BeginContext/EndContext traces
+func traceContexts(trace *Trace) []string {
+	if context, hasContext := trace.Operands["context"]; hasContext {
+		return append(append([]string{}, trace.ContextStack...), context)
+	}
+
+	return trace.ContextStack
+}
+
+// Returns the name of the component that pushed a context. Traced modules push contexts as
+// "<component name> <operation>(<arguments>)", and operation names have no spaces
+func contextComponent(context string) string {
+	call, _, _ := strings.Cut(context, "(")
+
+	if separator := strings.LastIndex(call, " "); separator >= 0 {
+		return call[:separator]
+	}
+
+	return call
+}
+
+// Returns true if the context was pushed by the given component. Whole component names are
+// matched, since names may contain spaces (e.g. "word" matches neither "word mov" nor
+// "word to float conversion")
+func pushedBy(context string, component string) bool {
+	return contextComponent(context) == component
+}
+
+func belongsToAny(trace *Trace, components []string) bool {
+	for _, context := range traceContexts(trace) {
+		for _, component := range components {
+			if pushedBy(context, component) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func (t *FilteredTracer) SaveTrace(trace *Trace) {
+	if len(t.allow) > 0 && !belongsToAny(trace, t.allow) {
+		return
+	}
+
+	if belongsToAny(trace, t.deny) {
+		return
+	}
+
+	t.Tracer.SaveTrace(trace)
+}
+
+// Returns the trace string indented by its context depth
+func indentedTraceString(trace *Trace) string {
+	return strings.Repeat(" ", trace.Depth()) + trace.String()
+}
+
+// Writes traces as indented text lines
+type TextTracer struct {
+	output io.Writer
+}
+
+func MakeTextTracer(output io.Writer) *TextTracer {
+	return &TextTracer{
+		output: output,
+	}
+}
+
+func (t *TextTracer) SaveTrace(trace *Trace) {
+	if _, err := fmt.Fprintln(t.output, indentedTraceString(trace)); err != nil {
+		fmt.Fprintf(os.Stderr, "could not write trace: %v\n", err)
+	}
+}
+
+// Buffered file writer that rotates the file when it grows past a maximum size
+type rotatingFile struct {
+	path     string
+	maxSize  int64
+	maxFiles int
+	file     *os.File
+	writer   *bufio.Writer
+	size     int64
+}
+
+func openRotatingFile(path string, maxSize int64, maxFiles int) (*rotatingFile, error) {
+	f := &rotatingFile{
+		path:     path,
+		maxSize:  maxSize,
+		maxFiles: maxFiles,
+	}
+
+	return f, f.open()
+}
+
+func (f *rotatingFile) open() error {
+	file, err := os.Create(f.path)
+
+	if err != nil {
+		return err
+	}
+
+	f.file = file
+	f.writer = bufio.NewWriter(file)
+	f.size = 0
+	return nil
+}
+
+func (f *rotatingFile) Close() error {
+	return errors.Join(f.writer.Flush(), f.file.Close())
+}
+
+// Returns the path of the n-th rotated file, where 0 is the current file
+func (f *rotatingFile) rotatedPath(n int) string {
+	if n == 0 {
+		return f.path
+	}
+
+	return fmt.Sprintf("%v.%v", f.path, n)
+}
+
+// Moves every file one position down in the rotation (path -> path.1, path.1 -> path.2, ...)
+// dropping the oldest one, then starts writing a new empty file
+func (f *rotatingFile) rotate() error {
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	for n := f.maxFiles - 1; n >= 0; n-- {
+		if n == f.maxFiles-1 {
+			os.Remove(f.rotatedPath(n))
+		} else if err := os.Rename(f.rotatedPath(n), f.rotatedPath(n+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return f.open()
+}
+
+func (f *rotatingFile) Write(data []byte) (int, error) {
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(data)) > f.maxSize {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := f.writer.Write(data)
+	f.size += int64(n)
+	return n, err
+}
+
+// Saves traces into a file, optionally rotating it by size. Safe for concurrent use.
+type FileTracer struct {
+	mutex  sync.Mutex
+	tracer Tracer
+	file   *rotatingFile
+}
+
+// Creates a tracer that saves traces into a file, formatted by the tracer returned by format.
+// If maxSize is greater than zero the file is rotated when it grows past maxSize bytes, keeping
+// up to maxFiles files in total (path, path.1, path.2, ...)
+func MakeFileTracer(path string, maxSize int64, maxFiles int, format func(io.Writer) (Tracer, error)) (*FileTracer, error) {
+	if maxFiles < 1 {
+		maxFiles = 1
+	}
+
+	file, err := openRotatingFile(path, maxSize, maxFiles)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tracer, err := format(file)
+
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return &FileTracer{
+		tracer: tracer,
+		file:   file,
+	}, nil
+}
+
+func (t *FileTracer) SaveTrace(trace *Trace) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.tracer.SaveTrace(trace)
+}
+
+// Flushes all buffered traces and closes the file
+func (t *FileTracer) Close() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return t.file.Close()
+}
+
+// Suppresses traces nested deeper than a maximum context depth. If summarizing, each run of
+// consecutive suppressed traces is replaced by a single "..." trace at the maximum depth + 1.
+type DepthLimitedTracer struct {
+	Tracer
+	maxDepth   int
+	summarize  bool
+	suppressed bool
+}
+
+func MakeDepthLimitedTracer(tracer Tracer, maxDepth int, summarize bool) *DepthLimitedTracer {
+	return &DepthLimitedTracer{
+		Tracer:    tracer,
+		maxDepth:  maxDepth,
+		summarize: summarize,
+	}
+}
+
+func (t *DepthLimitedTracer) SaveTrace(trace *Trace) {
+	if trace.Depth() <= t.maxDepth {
+		t.suppressed = false
+		t.Tracer.SaveTrace(trace)
+		return
+	}
+
+	if t.summarize && !t.suppressed {
+		t.Tracer.SaveTrace(&Trace{
+			Operation:    "...",
+			ContextStack: trace.ContextStack[:t.maxDepth+1],
+			Timestamp:    trace.Timestamp,
+		})
+	}
+
+	t.suppressed = true
+}
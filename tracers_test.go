@@ -0,0 +1,166 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Keeps all saved traces in memory
+type recordingTracer struct {
+	traces []*Trace
+}
+
+func (t *recordingTracer) SaveTrace(trace *Trace) {
+	t.traces = append(t.traces, trace)
+}
+
+func (t *recordingTracer) operations() []string {
+	operations := make([]string, 0, len(t.traces))
+
+	for _, trace := range t.traces {
+		operations = append(operations, trace.Operation)
+	}
+
+	return operations
+}
+
+func readFile(t *testing.T, path string) string {
+	content, err := os.ReadFile(path)
+	require.Nil(t, err)
+	return string(content)
+}
+
+func TestFileTracer_Rotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.txt")
+
+	// each trace line is 5 bytes ("tN  \n"), so each file holds two traces
+	tracer, err := MakeFileTracer(path, 12, 3, func(output io.Writer) (Tracer, error) {
+		return MakeTextTracer(output), nil
+	})
+	require.Nil(t, err)
+
+	for i := 1; i <= 7; i++ {
+		tracer.SaveTrace(&Trace{Operation: fmt.Sprintf("t%v", i)})
+	}
+
+	assert.Empty(t, readFile(t, path), "traces are buffered until the file is rotated or closed")
+	require.Nil(t, tracer.Close())
+
+	assert.Equal(t, "t7  \n", readFile(t, path))
+	assert.Equal(t, "t5  \nt6  \n", readFile(t, path+".1"))
+	assert.Equal(t, "t3  \nt4  \n", readFile(t, path+".2"))
+	assert.NoFileExists(t, path+".3", "only maxFiles files are kept")
+}
+
+func TestFileTracer_NoRotation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trace.txt")
+
+	tracer, err := MakeFileTracer(path, 0, 3, func(output io.Writer) (Tracer, error) {
+		return MakeTextTracer(output), nil
+	})
+	require.Nil(t, err)
+
+	for i := 1; i <= 3; i++ {
+		tracer.SaveTrace(&Trace{Operation: fmt.Sprintf("t%v", i)})
+	}
+
+	require.Nil(t, tracer.Close())
+	assert.Equal(t, "t1  \nt2  \nt3  \n", readFile(t, path))
+	assert.NoFileExists(t, path+".1")
+}
+
+func TestFilteredTracer(t *testing.T) {
+	traces := []*Trace{
+		{Operation: "mov", ContextStack: []string{"root", "word mov Move(src: w0, dest: w1)"}},
+		{Operation: "convert", ContextStack: []string{"root", "word to float conversion Convert(src: w0, dest: f0)"}},
+		{Operation: "read", ContextStack: []string{"root", "main memory bus Read(address: 0x0)"}},
+		{Operation: "load", ContextStack: []string{"root", "main memory access Load(address: w0, dest: w1)"}},
+		{Operation: "BeginContext", ContextStack: []string{"root"}, Operands: map[string]string{"context": "main memory bus Write(value: 1, address: 0x0)"}},
+	}
+
+	cases := []struct {
+		name     string
+		allow    []string
+		deny     []string
+		expected []string
+	}{
+		{name: "no filters", expected: []string{"mov", "convert", "read", "load", "BeginContext"}},
+		{name: "allow one component", allow: []string{"main memory bus"}, expected: []string{"read", "BeginContext"}},
+		{name: "allow several components", allow: []string{"word mov", "main memory access"}, expected: []string{"mov", "load"}},
+		{name: "deny one component", deny: []string{"word mov"}, expected: []string{"convert", "read", "load", "BeginContext"}},
+		{name: "deny takes precedence", allow: []string{"main memory bus", "main memory access"}, deny: []string{"main memory bus"}, expected: []string{"load"}},
+		{name: "partial names do not match", allow: []string{"word", "main memory"}, expected: []string{}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := &recordingTracer{}
+			tracer := MakeFilteredTracer(recorder, c.allow, c.deny)
+
+			for _, trace := range traces {
+				tracer.SaveTrace(trace)
+			}
+
+			assert.Equal(t, c.expected, recorder.operations())
+		})
+	}
+}
+
+func TestDepthLimitedTracer(t *testing.T) {
+	traceAtDepth := func(operation string, depth int) *Trace {
+		stack := make([]string, depth)
+
+		for i := range stack {
+			stack[i] = fmt.Sprintf("frame%v", i)
+		}
+
+		return &Trace{Operation: operation, ContextStack: stack}
+	}
+
+	traces := []*Trace{
+		traceAtDepth("a", 1),
+		traceAtDepth("b", 2),
+		traceAtDepth("c", 3),
+		traceAtDepth("d", 3),
+		traceAtDepth("e", 2),
+		traceAtDepth("f", 4),
+		traceAtDepth("g", 1),
+	}
+
+	cases := []struct {
+		name      string
+		maxDepth  int
+		summarize bool
+		expected  []string
+	}{
+		{name: "nothing suppressed", maxDepth: 4, summarize: true, expected: []string{"a", "b", "c", "d", "e", "f", "g"}},
+		{name: "suppressed", maxDepth: 2, summarize: false, expected: []string{"a", "b", "e", "g"}},
+		{name: "one summary per suppressed run", maxDepth: 2, summarize: true, expected: []string{"a", "b", "...", "e", "...", "g"}},
+		{name: "consecutive runs at different depths", maxDepth: 1, summarize: true, expected: []string{"a", "...", "g"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			recorder := &recordingTracer{}
+			tracer := MakeDepthLimitedTracer(recorder, c.maxDepth, c.summarize)
+
+			for _, trace := range traces {
+				tracer.SaveTrace(trace)
+			}
+
+			assert.Equal(t, c.expected, recorder.operations())
+
+			for _, trace := range recorder.traces {
+				if trace.Operation == "..." {
+					assert.Equal(t, c.maxDepth+1, trace.Depth(), "summaries are saved right below the maximum depth")
+				}
+			}
+		})
+	}
+}